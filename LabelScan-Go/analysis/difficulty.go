@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"LabelScan-Go/core"
+	"math"
+)
+
+// CalibrationMode 难度校准的方式
+type CalibrationMode int
+
+const (
+	// MeanOffset 目标距离 - 参考模型上的平均距离
+	MeanOffset CalibrationMode = iota
+	// ZScore (目标距离 - 参考均值) / 参考标准差
+	ZScore
+)
+
+// CalibrateDifficulty 按 Watson et al. (2022) 的思路做难度校准：
+// 有的样本天生离边界远 (简单样本)，有的天生很近 (困难样本)，
+// 用从没见过它的参考模型上的距离作为“难度基线”，再看目标模型比基线远多少。
+// 结果写入每条记录的 CalibratedScore；没有参考距离或目标攻击失败时记为 NaN。
+func CalibrateDifficulty(results []core.AttackResult, mode CalibrationMode) {
+	for i := range results {
+		r := &results[i]
+		if !r.IsSuccess || len(r.RefDistances) == 0 {
+			r.CalibratedScore = math.NaN()
+			continue
+		}
+
+		mean, std := meanStd(r.RefDistances)
+		offset := r.Distance - mean
+
+		// 只有一个参考模型或参考距离完全相同时，标准差为 0，退回均值差
+		if mode == ZScore && std > 0 {
+			r.CalibratedScore = offset / std
+		} else {
+			r.CalibratedScore = offset
+		}
+	}
+}
+
+func meanStd(x []float64) (float64, float64) {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	mean := sum / float64(len(x))
+
+	var sq float64
+	for _, v := range x {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(x)))
+}
@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"LabelScan-Go/core"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CanaryExposure 单个插入金丝雀的暴露度
+type CanaryExposure struct {
+	SampleID int
+	Distance float64
+	Rank     int     // 在 “自己 + 全部对照金丝雀” 中按距离从大到小的名次 (1 = 最像成员)
+	Exposure float64 // log2(对照数 + 1) - log2(Rank)
+}
+
+// ExposureReport 金丝雀暴露审计结果
+type ExposureReport struct {
+	Inserted     int
+	HeldOut      int
+	MeanInserted float64 // 插入金丝雀的平均边界距离
+	MeanHeldOut  float64 // 对照金丝雀的平均边界距离
+	AUC          float64 // 用距离区分插入/对照金丝雀的 AUC
+	MaxExposure  float64 // 理论上限 log2(对照数 + 1)
+	MeanExposure float64
+	TopExposed   float64          // 距离超过全部对照金丝雀的插入金丝雀比例
+	Canaries     []CanaryExposure // 按暴露度从高到低排序
+}
+
+// MeasureExposure 按 Carlini et al. “The Secret Sharer” 的暴露度定义，
+// 把每个插入金丝雀的边界距离放到对照金丝雀的距离分布里排名：
+// 排第 1 说明模型对它的“记忆”超过了所有没见过的同类金丝雀，暴露度达到上限。
+// results 按 IsMember 区分插入 (true) 与对照 (false)；攻击失败视为距离无穷大。
+func MeasureExposure(results []core.AttackResult) (*ExposureReport, error) {
+	var inserted, heldOut []core.AttackResult
+	for _, r := range results {
+		if r.IsMember {
+			inserted = append(inserted, r)
+		} else {
+			heldOut = append(heldOut, r)
+		}
+	}
+	if len(inserted) == 0 || len(heldOut) == 0 {
+		return nil, fmt.Errorf("需要同时有插入金丝雀和对照金丝雀 (插入 %d, 对照 %d)", len(inserted), len(heldOut))
+	}
+
+	ref := make([]float64, len(heldOut))
+	for i, r := range heldOut {
+		ref[i] = canaryDistance(r)
+	}
+	sort.Float64s(ref)
+
+	report := &ExposureReport{
+		Inserted:    len(inserted),
+		HeldOut:     len(heldOut),
+		MaxExposure: math.Log2(float64(len(heldOut) + 1)),
+	}
+
+	var wins float64 // Mann-Whitney 计数：插入距离 > 对照距离记 1，相等记 0.5
+	for _, r := range inserted {
+		d := canaryDistance(r)
+		below := sort.SearchFloat64s(ref, d)                                      // 对照中距离 < d 的个数
+		notAbove := sort.Search(len(ref), func(i int) bool { return ref[i] > d }) // 对照中距离 <= d 的个数
+		wins += float64(below) + 0.5*float64(notAbove-below)
+
+		rank := 1 + len(ref) - notAbove // 距离严格大于 d 的对照排在它前面，并列不吃亏
+		e := CanaryExposure{
+			SampleID: r.SampleID,
+			Distance: d,
+			Rank:     rank,
+			Exposure: report.MaxExposure - math.Log2(float64(rank)),
+		}
+		report.Canaries = append(report.Canaries, e)
+		report.MeanExposure += e.Exposure / float64(len(inserted))
+		if rank == 1 {
+			report.TopExposed += 1 / float64(len(inserted))
+		}
+	}
+	report.AUC = wins / float64(len(inserted)*len(heldOut))
+	report.MeanInserted = finiteMean(inserted)
+	report.MeanHeldOut = finiteMean(heldOut)
+
+	sort.SliceStable(report.Canaries, func(i, j int) bool {
+		return report.Canaries[i].Exposure > report.Canaries[j].Exposure
+	})
+	return report, nil
+}
+
+// String 输出暴露审计摘要
+func (r *ExposureReport) String() string {
+	return fmt.Sprintf(
+		"🐤 金丝雀暴露审计: 插入 %d / 对照 %d\n"+
+			"   平均距离: 插入 %.4f / 对照 %.4f，AUC %.4f\n"+
+			"   平均暴露度 %.3f / 上限 %.3f，%.1f%% 的插入金丝雀排名第一\n",
+		r.Inserted, r.HeldOut, r.MeanInserted, r.MeanHeldOut, r.AUC,
+		r.MeanExposure, r.MaxExposure, r.TopExposed*100)
+}
+
+// canaryDistance 攻击失败说明在预算内找不到边界，按最像成员处理
+func canaryDistance(r core.AttackResult) float64 {
+	if !r.IsSuccess {
+		return math.Inf(1)
+	}
+	return r.Distance
+}
+
+// finiteMean 只对攻击成功的距离取平均
+func finiteMean(results []core.AttackResult) float64 {
+	var sum float64
+	n := 0
+	for _, r := range results {
+		if r.IsSuccess {
+			sum += r.Distance
+			n++
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"LabelScan-Go/analysis"
+	"LabelScan-Go/core"
+	"LabelScan-Go/dataset"
+	"LabelScan-Go/worker"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 辅助攻击器：插入的金丝雀离边界更远 (距离 1)，对照金丝雀距离 0.5
+type memberAttacker struct{}
+
+func (memberAttacker) Attack(m core.Model, s core.Sample) core.AttackResult {
+	d := 0.5
+	if s.IsMember {
+		d = 1
+	}
+	return core.AttackResult{SampleID: s.ID, Distance: d, IsSuccess: true, IsMember: s.IsMember}
+}
+
+func TestMeasureExposure(t *testing.T) {
+	fmt.Println("=== 测试金丝雀暴露度 (手算参考值) ===")
+	results := []core.AttackResult{
+		{SampleID: 0, IsMember: true, IsSuccess: true, Distance: 0.5},
+		{SampleID: 1, IsMember: true, IsSuccess: true, Distance: 0.2},
+		{SampleID: 2, IsMember: true, IsSuccess: false},
+		{SampleID: 3, IsSuccess: true, Distance: 0.1},
+		{SampleID: 4, IsSuccess: true, Distance: 0.2},
+		{SampleID: 5, IsSuccess: true, Distance: 0.3},
+	}
+	r, err := analysis.MeasureExposure(results)
+	if err != nil {
+		t.Fatalf("计算失败: %v", err)
+	}
+	fmt.Print(r)
+
+	// 上限 log2(3 + 1) = 2；0.5 与攻击失败都排第 1，0.2 与对照并列时排第 2 (暴露度 1)
+	// AUC = (3 + 1.5 + 3) / 9；插入金丝雀的平均距离只算攻击成功的 (0.5 + 0.2) / 2
+	if r.MaxExposure != 2 || math.Abs(r.MeanExposure-5.0/3) > 1e-12 || math.Abs(r.TopExposed-2.0/3) > 1e-12 {
+		t.Errorf("暴露度不符: %+v", r)
+	}
+	if math.Abs(r.AUC-7.5/9) > 1e-12 || math.Abs(r.MeanInserted-0.35) > 1e-12 || math.Abs(r.MeanHeldOut-0.2) > 1e-12 {
+		t.Errorf("AUC / 平均距离不符: %+v", r)
+	}
+	last := r.Canaries[len(r.Canaries)-1]
+	if last.SampleID != 1 || last.Rank != 2 || last.Exposure != 1 {
+		t.Errorf("暴露度最低的应是样本 1 (第 2 名，暴露度 1), 实际 %+v", last)
+	}
+
+	if _, err := analysis.MeasureExposure(results[:3]); err == nil {
+		t.Error("没有对照金丝雀时应当报错")
+	}
+}
+
+func TestCanaryPhases(t *testing.T) {
+	fmt.Println("=== 测试金丝雀两阶段流程 (清单恢复种子编号) ===")
+	dir := t.TempDir()
+	inserted, heldOut := filepath.Join(dir, "inserted"), filepath.Join(dir, "heldout")
+	cfg := dataset.CanaryConfig{Inserted: 4, HeldOut: 6, Seed: 7}
+	if err := writeCanaries(cfg, inserted, heldOut); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+
+	// 读回后 ID / 文件名与生成时一致，像素逐位相同
+	want, _ := dataset.GenerateCanaries(cfg)
+	got, err := dataset.LoadCanaries(inserted+".bin", inserted+".json")
+	if err != nil {
+		t.Fatalf("读回失败: %v", err)
+	}
+	for i, s := range got {
+		if s.ID != want[i].ID || s.Filename != want[i].Filename || !s.IsMember || s.Label != want[i].Label {
+			t.Errorf("第 %d 个金丝雀元数据不符: %+v", i, s)
+		}
+		for j := range s.Data {
+			if s.Data[j] != want[i].Data[j] {
+				t.Fatalf("第 %d 个金丝雀像素 %d 不一致", i, j)
+			}
+		}
+	}
+
+	report, err := auditCanaryExposure(worker.NewAuditor(&MockModel{}, memberAttacker{}, 2), inserted, heldOut)
+	if err != nil {
+		t.Fatalf("审计失败: %v", err)
+	}
+	if report.Inserted != 4 || report.HeldOut != 6 || report.TopExposed != 1 || report.Canaries[0].SampleID >= 4 {
+		t.Errorf("暴露审计不符: %+v", report)
+	}
+	for _, c := range report.Canaries {
+		if c.SampleID > 3 {
+			t.Errorf("插入金丝雀应保留生成时的 ID 0..3, 实际 %d", c.SampleID)
+		}
+	}
+
+	// 清单与二进制对不上时报错
+	os.WriteFile(heldOut+".json", []byte(`[]`), 0o644)
+	if _, err := auditCanaryExposure(worker.NewAuditor(&MockModel{}, memberAttacker{}, 2), inserted, heldOut); err == nil {
+		t.Error("清单记录数不符时应当报错")
+	}
+	if err := dataset.WriteCifarBatch(filepath.Join(dir, "bad.bin"), []core.Sample{{Label: 300, Data: make(core.Image, 3072)}}); err == nil {
+		t.Error("标签超过 255 时写出应当报错")
+	}
+}
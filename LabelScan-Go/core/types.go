@@ -20,6 +20,9 @@ type AttackResult struct {
 	Queries       int     // 攻击这张图查了多少次 API
 	Distance      float64 // 到决策边界的距离（核心指标）
 	IsMember      bool    // 样本真身
+
+	RefDistances    []float64 // 同一攻击在各参考模型上的距离（攻击失败的不计入）
+	CalibratedScore float64   // 难度校准后的成员分数，由 analysis.CalibrateDifficulty 填写
 }
 
 // Model 接口：队长实现的对讲机（你现在要求他必须支持批量）
@@ -0,0 +1,207 @@
+package dataset
+
+import (
+	"LabelScan-Go/core"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+// CanaryConfig 金丝雀生成参数
+type CanaryConfig struct {
+	Inserted   int   // 要插入训练集的金丝雀数量
+	HeldOut    int   // 同一生成器产生、但不插入训练集的对照金丝雀数量
+	NumClasses int   // 随机标签的类别数 (默认 10)
+	Seed       int64 // 相同种子生成完全相同的金丝雀
+}
+
+// GenerateCanaries 生成分布外 (OOD) 的金丝雀图片并随机指定标签。
+// 图片是噪声、条纹、棋盘格、色块这类自然图片里不会出现的图案，标签与内容无关，
+// 模型只能靠死记硬背把它们分对 —— 插入的金丝雀被记住的程度就是隐私泄露的上界。
+// 像素已量化到 1/255，写出 CIFAR 二进制再读回后数值完全一致。
+func GenerateCanaries(cfg CanaryConfig) (inserted, heldOut []core.Sample) {
+	if cfg.NumClasses == 0 {
+		cfg.NumClasses = 10
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	total := cfg.Inserted + cfg.HeldOut
+	for i := 0; i < total; i++ {
+		s := core.Sample{
+			ID:       i,
+			Data:     canaryImage(rng),
+			Label:    rng.Intn(cfg.NumClasses),
+			IsMember: i < cfg.Inserted,
+			Filename: fmt.Sprintf("canary_seed%d_#%d", cfg.Seed, i),
+		}
+		if s.IsMember {
+			inserted = append(inserted, s)
+		} else {
+			heldOut = append(heldOut, s)
+		}
+	}
+	return inserted, heldOut
+}
+
+// WriteCifarBatch 按 CIFAR-10 二进制格式 (1 字节标签 + 3072 字节像素) 写出样本，
+// 可直接拼接到 data_batch_*.bin 后面参与训练，也能被 CifarLoader.LoadBatch 读回
+func WriteCifarBatch(path string, samples []core.Sample) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	record := make([]byte, 3073)
+	for _, s := range samples {
+		if s.Label < 0 || s.Label > 255 || len(s.Data) != 3072 {
+			return fmt.Errorf("样本 %d 无法写成 CIFAR 记录 (标签 %d, 像素数 %d)", s.ID, s.Label, len(s.Data))
+		}
+		record[0] = byte(s.Label)
+		for i, v := range s.Data {
+			record[i+1] = toByte(v)
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// CanaryManifestEntry 旁路清单中的一条记录，与 CIFAR 二进制中的记录按顺序一一对应
+type CanaryManifestEntry struct {
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+	Label    int    `json:"label"`
+	Inserted bool   `json:"inserted"`
+}
+
+// WriteCanaryManifest 把金丝雀的 ID、文件名与插入标记写成 JSON 旁路清单。
+// CIFAR 二进制只保存标签和像素，读回后 ID 会变成文件内序号，靠这份清单才能对上生成时的种子编号。
+func WriteCanaryManifest(path string, samples []core.Sample) error {
+	entries := make([]CanaryManifestEntry, len(samples))
+	for i, s := range samples {
+		entries[i] = CanaryManifestEntry{ID: s.ID, Filename: s.Filename, Label: s.Label, Inserted: s.IsMember}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadCanaries 读回 WriteCifarBatch 写出的金丝雀，并按旁路清单恢复 ID、Filename 与 IsMember。
+// 记录数或标签与清单对不上 (例如二进制被换成了别的文件) 时报错。
+func LoadCanaries(batchPath, manifestPath string) ([]core.Sample, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var entries []CanaryManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析金丝雀清单 %s 失败: %w", manifestPath, err)
+	}
+
+	samples, err := (&CifarLoader{}).LoadBatch(batchPath, -1)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) != len(entries) {
+		return nil, fmt.Errorf("%s 有 %d 条记录，清单 %s 有 %d 条", batchPath, len(samples), manifestPath, len(entries))
+	}
+	for i, e := range entries {
+		if samples[i].Label != e.Label {
+			return nil, fmt.Errorf("%s 第 %d 条记录的标签 %d 与清单中的 %d 不一致", batchPath, i, samples[i].Label, e.Label)
+		}
+		samples[i].ID = e.ID
+		samples[i].Filename = e.Filename
+		samples[i].IsMember = e.Inserted
+	}
+	return samples, nil
+}
+
+// canaryImage 随机选一种 OOD 图案生成 3x32x32 (CHW) 图片
+func canaryImage(rng *rand.Rand) core.Image {
+	img := make(core.Image, 3072)
+	color := func() [3]float32 {
+		return [3]float32{rng.Float32(), rng.Float32(), rng.Float32()}
+	}
+	set := func(y, x int, c [3]float32) {
+		for ch := 0; ch < 3; ch++ {
+			img[ch*1024+y*32+x] = c[ch]
+		}
+	}
+
+	switch rng.Intn(4) {
+	case 0: // 均匀噪声
+		for i := range img {
+			img[i] = rng.Float32()
+		}
+	case 1: // 随机宽度、方向的双色条纹
+		a, b := color(), color()
+		width := 1 + rng.Intn(4)
+		vertical := rng.Intn(2) == 0
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				k := y
+				if vertical {
+					k = x
+				}
+				if (k/width)%2 == 0 {
+					set(y, x, a)
+				} else {
+					set(y, x, b)
+				}
+			}
+		}
+	case 2: // 双色棋盘格
+		a, b := color(), color()
+		cell := 2 + rng.Intn(6)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				if (y/cell+x/cell)%2 == 0 {
+					set(y, x, a)
+				} else {
+					set(y, x, b)
+				}
+			}
+		}
+	default: // 纯色背景上叠加若干随机色块
+		bg := color()
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				set(y, x, bg)
+			}
+		}
+		for n := 3 + rng.Intn(5); n > 0; n-- {
+			c := color()
+			y0, x0 := rng.Intn(32), rng.Intn(32)
+			h, w := 1+rng.Intn(16), 1+rng.Intn(16)
+			for y := y0; y < y0+h && y < 32; y++ {
+				for x := x0; x < x0+w && x < 32; x++ {
+					set(y, x, c)
+				}
+			}
+		}
+	}
+
+	// 量化到 8 位，保证与写出的 CIFAR 文件一致
+	for i, v := range img {
+		img[i] = float32(toByte(v)) / 255.0
+	}
+	return img
+}
+
+// toByte 把 [0, 1] 的像素值四舍五入为 0~255
+func toByte(v float32) byte {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return byte(v*255 + 0.5)
+}
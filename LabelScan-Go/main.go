@@ -1,9 +1,14 @@
 package main
 
 import (
+	"LabelScan-Go/analysis"
 	"LabelScan-Go/core"
 	"LabelScan-Go/dataset"
 	"LabelScan-Go/worker"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 )
 
 // --- 模拟对象 (等到联调时换成队长的真实代码) ---
@@ -26,7 +31,44 @@ func (a *MockAttacker) Attack(m core.Model, s core.Sample) core.AttackResult {
 	}
 }
 
+// 用法:
+//
+//	go run .                  # 成员 / 非成员审计，导出 final_audit_score.csv
+//	go run . -gen-canaries    # 第一阶段：生成金丝雀，写出 CIFAR 二进制与旁路清单，把插入的那份拼进训练集
+//	go run . -audit-canaries  # 第二阶段：模型训练完后读回金丝雀，攻击并输出暴露度
 func main() {
+	genCanaries := flag.Bool("gen-canaries", false, "生成金丝雀并写出 CIFAR 二进制 (训练前)")
+	auditCanaries := flag.Bool("audit-canaries", false, "读回金丝雀并做暴露审计 (训练后)")
+	canaryDir := flag.String("canary-dir", "data", "金丝雀文件所在目录")
+	canarySeed := flag.Int64("canary-seed", 2024, "金丝雀生成种子")
+	canaryInserted := flag.Int("canaries-inserted", 50, "插入训练集的金丝雀数量")
+	canaryHeldOut := flag.Int("canaries-heldout", 50, "对照金丝雀数量")
+	flag.Parse()
+
+	insertedPath := filepath.Join(*canaryDir, "canaries_inserted")
+	heldOutPath := filepath.Join(*canaryDir, "canaries_heldout")
+
+	switch {
+	case *genCanaries:
+		cfg := dataset.CanaryConfig{Inserted: *canaryInserted, HeldOut: *canaryHeldOut, Seed: *canarySeed}
+		if err := writeCanaries(cfg, insertedPath, heldOutPath); err != nil {
+			fail("金丝雀生成失败", err)
+		}
+		fmt.Printf("🐤 已写出 %d 个插入金丝雀 (%s.bin) 与 %d 个对照金丝雀 (%s.bin)\n", *canaryInserted, insertedPath, *canaryHeldOut, heldOutPath)
+	case *auditCanaries:
+		auditor := worker.NewAuditor(&MockModel{}, &MockAttacker{}, 20) // 暴露度不需要参考模型
+		exposure, err := auditCanaryExposure(auditor, insertedPath, heldOutPath)
+		if err != nil {
+			fail("金丝雀审计失败", err)
+		}
+		fmt.Print(exposure)
+	default:
+		runAudit()
+	}
+}
+
+// runAudit 成员 / 非成员审计 (Task 1 ~ 4)
+func runAudit() {
 	// 1. 加载 100 张成员和 100 张非成员 (Task 1)
 	mLoader := &dataset.CifarLoader{IsMemberSet: true}
 	nmLoader := &dataset.CifarLoader{IsMemberSet: false}
@@ -40,8 +82,47 @@ func main() {
 
 	// 3. 通用高并发审计 (Task 3)
 	auditor := worker.NewAuditor(&MockModel{}, &MockAttacker{}, 20)
+	auditor.RefModels = []core.Model{&MockModel{}, &MockModel{}} // 参考模型 (没见过审计样本)
 	finalResults := auditor.RunAudit(allSamples)
 
+	// 3.1 难度校准：目标距离相对参考模型距离的 z 分数
+	analysis.CalibrateDifficulty(finalResults, analysis.ZScore)
+
 	// 4. 导出 CSV (持久化)
 	ExportAttackResults(finalResults, "final_audit_score.csv")
 }
+
+// writeCanaries 生成金丝雀，按 <前缀>.bin 写出 CIFAR 二进制、<前缀>.json 写出旁路清单
+func writeCanaries(cfg dataset.CanaryConfig, insertedPath, heldOutPath string) error {
+	inserted, heldOut := dataset.GenerateCanaries(cfg)
+	for _, out := range []struct {
+		path    string
+		samples []core.Sample
+	}{{insertedPath, inserted}, {heldOutPath, heldOut}} {
+		if err := dataset.WriteCifarBatch(out.path+".bin", out.samples); err != nil {
+			return err
+		}
+		if err := dataset.WriteCanaryManifest(out.path+".json", out.samples); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditCanaryExposure 读回两份金丝雀 (恢复生成时的 ID)，只攻击目标模型并计算暴露度
+func auditCanaryExposure(auditor *worker.Auditor, insertedPath, heldOutPath string) (*analysis.ExposureReport, error) {
+	inserted, err := dataset.LoadCanaries(insertedPath+".bin", insertedPath+".json")
+	if err != nil {
+		return nil, err
+	}
+	heldOut, err := dataset.LoadCanaries(heldOutPath+".bin", heldOutPath+".json")
+	if err != nil {
+		return nil, err
+	}
+	return analysis.MeasureExposure(auditor.TargetOnly().RunAudit(append(inserted, heldOut...)))
+}
+
+func fail(msg string, err error) {
+	fmt.Fprintf(os.Stderr, "❌ %s: %v\n", msg, err)
+	os.Exit(1)
+}
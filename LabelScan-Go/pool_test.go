@@ -0,0 +1,84 @@
+package main
+
+import (
+	"LabelScan-Go/analysis"
+	"LabelScan-Go/core"
+	"LabelScan-Go/worker"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+// 辅助模型：只带一个边界距离，供 distanceAttacker 读取
+type stubModel struct {
+	MockModel
+	dist float64
+}
+
+// 辅助攻击器：直接返回模型自带的距离，并按模型记录被攻击的次数
+type distanceAttacker struct {
+	mu    sync.Mutex
+	calls map[*stubModel]int
+}
+
+func (a *distanceAttacker) Attack(m core.Model, s core.Sample) core.AttackResult {
+	sm := m.(*stubModel)
+	a.mu.Lock()
+	a.calls[sm]++
+	a.mu.Unlock()
+	return core.AttackResult{SampleID: s.ID, Distance: sm.dist, IsSuccess: sm.dist > 0, IsMember: s.IsMember}
+}
+
+func TestAuditorReferenceModels(t *testing.T) {
+	fmt.Println("=== 测试审计器 (参考模型与难度校准) ===")
+	target := &stubModel{dist: 1.0}
+	refA, refB, refFailed := &stubModel{dist: 0.4}, &stubModel{dist: 0.6}, &stubModel{dist: 0}
+	atk := &distanceAttacker{calls: make(map[*stubModel]int)}
+
+	auditor := worker.NewAuditor(target, atk, 2)
+	auditor.RefModels = []core.Model{refA, refB, refFailed}
+	samples := []core.Sample{{ID: 0}, {ID: 1}}
+	results := auditor.RunAudit(samples)
+
+	// 参考距离 [0.4, 0.6] (失败的不计入)：均值 0.5，标准差 0.1
+	analysis.CalibrateDifficulty(results, analysis.ZScore)
+	for _, r := range results {
+		if len(r.RefDistances) != 2 || math.Abs(r.CalibratedScore-5) > 1e-9 {
+			t.Errorf("样本 %d: 期望 2 个参考距离、z 分数 5, 实际 %v / %.4f", r.SampleID, r.RefDistances, r.CalibratedScore)
+		}
+	}
+	analysis.CalibrateDifficulty(results, analysis.MeanOffset)
+	if math.Abs(results[0].CalibratedScore-0.5) > 1e-9 {
+		t.Errorf("均值差期望 0.5, 实际 %.4f", results[0].CalibratedScore)
+	}
+
+	// 只攻击目标模型：参考模型上不再发生任何攻击
+	for _, r := range auditor.TargetOnly().RunAudit(samples) {
+		if r.RefDistances != nil {
+			t.Errorf("TargetOnly 不应填写参考距离: %v", r.RefDistances)
+		}
+	}
+	if atk.calls[refA] != 2 || atk.calls[target] != 4 {
+		t.Errorf("TargetOnly 不应攻击参考模型: 参考 %d 次, 目标 %d 次", atk.calls[refA], atk.calls[target])
+	}
+	if len(auditor.RefModels) != 3 {
+		t.Error("TargetOnly 不应修改原审计器")
+	}
+}
+
+func TestCalibrateDifficultyMissing(t *testing.T) {
+	fmt.Println("=== 测试难度校准 (无参考距离或攻击失败) ===")
+	results := []core.AttackResult{
+		{IsSuccess: false, RefDistances: []float64{0.5}},
+		{IsSuccess: true, Distance: 0.7},
+		{IsSuccess: true, Distance: 0.7, RefDistances: []float64{0.5}}, // 标准差为 0，退回均值差
+	}
+	analysis.CalibrateDifficulty(results, analysis.ZScore)
+	if !math.IsNaN(results[0].CalibratedScore) || !math.IsNaN(results[1].CalibratedScore) {
+		t.Errorf("期望 NaN, 实际 %.4f / %.4f", results[0].CalibratedScore, results[1].CalibratedScore)
+	}
+	if math.Abs(results[2].CalibratedScore-0.2) > 1e-9 {
+		t.Errorf("期望 0.2, 实际 %.4f", results[2].CalibratedScore)
+	}
+}
@@ -15,7 +15,7 @@ func ExportAttackResults(results []core.AttackResult, filename string) {
 	w := csv.NewWriter(file)
 	defer w.Flush()
 
-	w.Write([]string{"id", "orig", "final", "success", "queries", "distance", "is_member"})
+	w.Write([]string{"id", "orig", "final", "success", "queries", "distance", "calibrated_score", "is_member"})
 	for _, r := range results {
 		w.Write([]string{
 			strconv.Itoa(r.SampleID),
@@ -24,6 +24,7 @@ func ExportAttackResults(results []core.AttackResult, filename string) {
 			strconv.FormatBool(r.IsSuccess),
 			strconv.Itoa(r.Queries),
 			fmt.Sprintf("%.6f", r.Distance),
+			fmt.Sprintf("%.6f", r.CalibratedScore),
 			strconv.FormatBool(r.IsMember),
 		})
 	}
@@ -10,12 +10,24 @@ type Auditor struct {
 	Model       core.Model
 	Attacker    core.Attacker
 	WorkerCount int
+
+	// RefModels 参考模型：从没见过审计样本的本地模型 (Watson et al. 难度校准)
+	// 非空时，每个样本还会在这些模型上各攻击一次，距离写入 RefDistances
+	RefModels []core.Model
 }
 
 func NewAuditor(m core.Model, a core.Attacker, count int) *Auditor {
 	return &Auditor{Model: m, Attacker: a, WorkerCount: count}
 }
 
+// TargetOnly 返回不带参考模型的审计器副本。
+// 不需要难度校准的审计 (例如金丝雀暴露度) 用它，省得在每个参考模型上再攻击一遍。
+func (a *Auditor) TargetOnly() *Auditor {
+	c := *a
+	c.RefModels = nil
+	return &c
+}
+
 // RunAudit 让 20 个工人同时跑复杂的 Attack 函数
 func (a *Auditor) RunAudit(samples []core.Sample) []core.AttackResult {
 	var wg sync.WaitGroup
@@ -31,6 +43,7 @@ func (a *Auditor) RunAudit(samples []core.Sample) []core.AttackResult {
 			for s := range jobs {
 				// 运行队长写的攻击逻辑，并将 Model 借给他用
 				res := a.Attacker.Attack(a.Model, s)
+				res.RefDistances = a.referenceDistances(s)
 				resultsChan <- res
 			}
 		}()
@@ -53,3 +66,19 @@ func (a *Auditor) RunAudit(samples []core.Sample) []core.AttackResult {
 	}
 	return finalResults
 }
+
+// referenceDistances 用同一个攻击器在每个参考模型上攻击样本，返回成功攻击的距离
+func (a *Auditor) referenceDistances(s core.Sample) []float64 {
+	if len(a.RefModels) == 0 {
+		return nil
+	}
+
+	dists := make([]float64, 0, len(a.RefModels))
+	for _, m := range a.RefModels {
+		res := a.Attacker.Attack(m, s)
+		if res.IsSuccess {
+			dists = append(dists, res.Distance)
+		}
+	}
+	return dists
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"label-only-mia-go/pkg/attack"
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/dataset"
+	"label-only-mia-go/pkg/eval"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// 辅助函数：全零图片，pixelModel 判为 0；只要第 0 个像素超过 0.5 标签就会翻转
+func zeroSample(id int) core.Sample {
+	return core.Sample{ID: id, Data: make(core.Image, core.FlattenedSize), Label: 0}
+}
+
+func TestPointwiseFindsSinglePixel(t *testing.T) {
+	fmt.Println("=== 测试 Pointwise (只有一个像素决定标签) ===")
+	mathutils.SetSeed(1)
+	atk := attack.NewPointwise(attack.PointwiseConfig{MaxQueries: 10000, ClipMin: 0, ClipMax: 1})
+
+	res := atk.Attack(zeroSample(0), pixelModel{})
+	fmt.Printf("  L0 = %.0f, 查询 %d 次\n", res.Distance, res.Queries)
+	if !res.IsSuccess || res.Distance != 1 {
+		t.Errorf("期望只剩第 0 个像素被改动 (L0 = 1), 实际 %+v", res)
+	}
+}
+
+// 辅助模型：第 0 个像素位置的 R、G 两个通道都超过 0.5 时判为 1
+type rgPixelModel struct{ pixelModel }
+
+func (rgPixelModel) Predict(img core.Image) (int, error) {
+	if img[0] > 0.5 && img[core.ImgHeight*core.ImgWidth] > 0.5 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m rgPixelModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	labels := make([]int, len(imgs))
+	for i, img := range imgs {
+		labels[i], _ = m.Predict(img)
+	}
+	return labels, nil
+}
+
+func TestPointwiseCountsPixels(t *testing.T) {
+	fmt.Println("=== 测试 Pointwise (零值配置，按像素位置计数) ===")
+	mathutils.SetSeed(1)
+	atk := attack.NewPointwise(attack.PointwiseConfig{ClipMin: 0, ClipMax: 1})
+
+	// 同一位置的两个通道必须保留改动，但只算 1 个像素
+	res := atk.Attack(zeroSample(0), rgPixelModel{})
+	fmt.Printf("  L0 = %.0f, 查询 %d 次\n", res.Distance, res.Queries)
+	if !res.IsSuccess || res.Distance != 1 {
+		t.Errorf("零值配置应使用默认预算并恢复到 1 个像素, 实际 %+v", res)
+	}
+}
+
+func TestPointwiseBudget(t *testing.T) {
+	fmt.Println("=== 测试 Pointwise (最后的确认查询也计入预算) ===")
+	mathutils.SetSeed(1)
+	atk := attack.NewPointwise(attack.PointwiseConfig{MaxQueries: 50, ClipMin: 0, ClipMax: 1})
+
+	res := atk.Attack(zeroSample(0), pixelModel{})
+	if res.Queries != 50 {
+		t.Errorf("期望恰好用完 50 次查询, 实际 %d", res.Queries)
+	}
+	if !res.IsSuccess {
+		t.Errorf("预算耗尽时仍应返回对抗样本: %+v", res)
+	}
+}
+
+// 辅助模型：第 0 个像素落在 [0.15, 0.5] 内判为 0，否则为 1
+type bandModel struct{}
+
+func (bandModel) Predict(img core.Image) (int, error) {
+	if img[0] >= 0.15 && img[0] <= 0.5 {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (m bandModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	labels := make([]int, len(imgs))
+	for i, img := range imgs {
+		labels[i], _ = m.Predict(img)
+	}
+	return labels, nil
+}
+
+func (bandModel) GetInputSize() int { return core.FlattenedSize }
+
+// 辅助函数：所有像素都为 v 的样本
+func constSample(id int, v float32, label int) core.Sample {
+	return core.Sample{ID: id, Data: mathutils.NewVector(core.FlattenedSize, v), Label: label}
+}
+
+func TestTransformBrightness(t *testing.T) {
+	fmt.Println("=== 测试 TransformAttack (亮度翻转) ===")
+	// 常数图片只有亮度能改变第 0 个像素：0.3 + 0.5t > 0.5  =>  t > 0.4
+	atk := attack.NewTransformAttack(attack.TransformConfig{ClipMin: 0, ClipMax: 1})
+	res := atk.Attack(constSample(0, 0.3, 0), pixelModel{})
+	fmt.Printf("  幅度 = %.4f, 查询 %d 次\n", res.Distance, res.Queries)
+	if !res.IsSuccess || math.Abs(res.Distance-0.4) > 1e-3 {
+		t.Errorf("期望幅度约为 0.4, 实际 %+v", res)
+	}
+}
+
+func TestTransformFlipBelowLimit(t *testing.T) {
+	fmt.Println("=== 测试 TransformAttack (翻转点落在上一个最优幅度与下一个格点之间) ===")
+	// 变亮在 t = 0.4 翻转；变暗 0.3 - 0.5t < 0.15 在 t = 0.3 翻转，
+	// 但格点只有 0.5 和 1.0，变暗方向必须探测到 limit = 0.4 才能发现
+	atk := attack.NewTransformAttack(attack.TransformConfig{
+		Kinds: []attack.TransformKind{attack.TransformBrightness}, GridSteps: 2, ClipMin: 0, ClipMax: 1,
+	})
+	res := atk.Attack(constSample(0, 0.3, 0), bandModel{})
+	if !res.IsSuccess || math.Abs(res.Distance-0.3) > 1e-3 {
+		t.Errorf("期望幅度约为 0.3, 实际 %+v", res)
+	}
+}
+
+func TestTransformLargeScaleAndBadShape(t *testing.T) {
+	fmt.Println("=== 测试 TransformAttack (MaxScale >= 1 与非 3x32x32 输入不 panic) ===")
+	atk := attack.NewTransformAttack(attack.TransformConfig{
+		Kinds: []attack.TransformKind{attack.TransformScale}, MaxScale: 1.5, ClipMin: 0, ClipMax: 1,
+	})
+	atk.Attack(constSample(0, 0.3, 0), pixelModel{})
+
+	res := atk.Attack(core.Sample{ID: 1, Data: make(core.Image, 10)}, pixelModel{})
+	if res.IsSuccess || res.Queries != 0 {
+		t.Errorf("尺寸不对时应直接返回失败结果, 实际 %+v", res)
+	}
+}
+
+func TestEvolutionaryImprovesWithBudget(t *testing.T) {
+	fmt.Println("=== 测试 Evolutionary (预算越多距离越短，且不低于真实最小距离 0.5) ===")
+	// 同一种子下，大预算的轨迹以小预算的轨迹为前缀，而距离只会缩短
+	var dists []float64
+	for _, budget := range []int{1, 500, 3000} {
+		mathutils.SetSeed(3)
+		atk := attack.NewEvolutionary(attack.EvolutionaryConfig{MaxQueries: budget, ClipMin: 0, ClipMax: 1})
+		res := atk.Attack(zeroSample(0), pixelModel{})
+		if !res.IsSuccess {
+			t.Fatalf("预算 %d: 攻击失败 %+v", budget, res)
+		}
+		fmt.Printf("  预算 %4d: 距离 %.4f\n", budget, res.Distance)
+		dists = append(dists, res.Distance)
+	}
+
+	if dists[2] >= dists[0] || dists[2] > dists[1] {
+		t.Errorf("距离应随预算缩短: %v", dists)
+	}
+	if dists[2] < 0.5-1e-6 {
+		t.Errorf("距离 %.4f 低于第 0 个像素翻转所需的 0.5", dists[2])
+	}
+}
+
+func TestHSJASharedHelpers(t *testing.T) {
+	fmt.Println("=== 测试 HSJA (共用初始化与二分) ===")
+	mathutils.SetSeed(4)
+	atk := attack.NewHSJA(attack.HSJAConfig{MaxQueries: 2000, NumEvals: 50, ClipMin: 0, ClipMax: 1})
+	res := atk.Attack(zeroSample(0), pixelModel{})
+	fmt.Printf("  距离 %.4f, 查询 %d 次\n", res.Distance, res.Queries)
+	if !res.IsSuccess || res.Distance < 0.5-1e-6 {
+		t.Errorf("期望成功且距离不低于 0.5, 实际 %+v", res)
+	}
+}
+
+func TestBlendNearestNeighbor(t *testing.T) {
+	fmt.Println("=== 测试 Blend (最近异类邻居，跳过模型不认可的参考图) ===")
+	wrong := core.Sample{ID: 10, Data: make(core.Image, core.FlattenedSize), Label: 1} // 标成 1 但模型判为 0
+	near := core.Sample{ID: 11, Data: make(core.Image, core.FlattenedSize), Label: 1}
+	near.Data[0] = 1
+	far := constSample(12, 1, 1)
+
+	atk := attack.NewBlend(attack.BlendConfig{
+		References: []core.Sample{far, near, wrong}, Policy: attack.NeighborNearest, ClipMin: 0, ClipMax: 1,
+	})
+	res := atk.Attack(zeroSample(0), pixelModel{})
+	fmt.Printf("  距离 %.4f, 查询 %d 次\n", res.Distance, res.Queries)
+
+	// 与 near 的连线上 t > 0.5 时翻转，10 次二分后 t = 0.5 + 1/1024
+	if !res.IsSuccess || math.Abs(res.Distance-(0.5+1.0/1024)) > 1e-6 {
+		t.Errorf("期望距离 %.6f, 实际 %+v", 0.5+1.0/1024, res)
+	}
+	// 1 次被拒的端点 + 1 次被接受的端点 + 10 次二分 + 1 次确认
+	if res.Queries != 13 {
+		t.Errorf("期望 13 次查询, 实际 %d", res.Queries)
+	}
+}
+
+func TestBlendPrototype(t *testing.T) {
+	fmt.Println("=== 测试 Blend (类原型) ===")
+	// 类 1 的原型是两张图的均值：所有像素为 0.5，第 0 个像素为 1
+	a, b := constSample(1, 0, 1), constSample(2, 1, 1)
+	a.Data[0], b.Data[0] = 1, 1
+
+	atk := attack.NewBlend(attack.BlendConfig{References: []core.Sample{a, b}, Policy: attack.NeighborPrototype, ClipMin: 0, ClipMax: 1})
+	res := atk.Attack(zeroSample(0), pixelModel{})
+
+	proto := mathutils.NewVector(core.FlattenedSize, 0.5)
+	proto[0] = 1
+	want := (0.5 + 1.0/1024) * mathutils.L2Norm(proto)
+	if !res.IsSuccess || math.Abs(res.Distance-want) > 1e-4 {
+		t.Errorf("期望距离 %.4f, 实际 %+v", want, res)
+	}
+}
+
+func TestBlendPrototypeTiesDeterministic(t *testing.T) {
+	fmt.Println("=== 测试 Blend (距离相同的原型按标签排序) ===")
+	// 类 1、类 2 的原型与全零样本距离相同，只有类 1 的原型会被判为异类
+	one, two := zeroSample(1), zeroSample(2)
+	one.Label, two.Label = 1, 2
+	one.Data[0], two.Data[1] = 1, 1
+
+	atk := attack.NewBlend(attack.BlendConfig{
+		References: []core.Sample{two, one}, Policy: attack.NeighborPrototype, MaxCandidates: 1, ClipMin: 0, ClipMax: 1,
+	})
+	for i := 0; i < 20; i++ {
+		if res := atk.Attack(zeroSample(0), pixelModel{}); !res.IsSuccess || res.Queries != 12 {
+			t.Fatalf("第 %d 次: 距离相同时应固定先试类 1 的原型, 实际 %+v", i, res)
+		}
+	}
+}
+
+func TestHSJAWarmStart(t *testing.T) {
+	fmt.Println("=== 测试 HSJA 热启动 (同类样本复用历史方向) ===")
+	mathutils.SetSeed(5)
+	cache := attack.NewWarmStartCache(0, 0)
+	atk := attack.NewHSJA(attack.HSJAConfig{MaxQueries: 500, NumEvals: 20, ClipMin: 0, ClipMax: 1, WarmStart: cache})
+
+	first := atk.Attack(zeroSample(0), pixelModel{})
+	second := atk.Attack(zeroSample(1), pixelModel{})
+	if first.WarmStarted || !second.WarmStarted {
+		t.Errorf("期望只有第二次攻击使用热启动: %v / %v", first.WarmStarted, second.WarmStarted)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("两次成功攻击后缓存应有 2 条记录, 实际 %d", cache.Len())
+	}
+}
+
+func TestHSJAWarmStartKeepsMisclassifiedOriginal(t *testing.T) {
+	fmt.Println("=== 测试 HSJA 热启动 (原图已被误分类时距离仍为 0) ===")
+	cache := attack.NewWarmStartCache(0, 0)
+	// 缓存里的方向只改第 1 个像素，走出去的点同样被判为 0，旧的顺序会把它当作热启动点
+	adv := make(core.Image, core.FlattenedSize)
+	adv[1] = 0.5
+	cache.Store(1, make(core.Image, core.FlattenedSize), adv)
+
+	atk := attack.NewHSJA(attack.HSJAConfig{MaxQueries: 100, NumEvals: 10, ClipMin: 0, ClipMax: 1, WarmStart: cache})
+	// 全零图片被 pixelModel 判为 0，而真实标签是 1
+	sample := core.Sample{ID: 0, Data: make(core.Image, core.FlattenedSize), Label: 1}
+	res := atk.Attack(sample, pixelModel{})
+	if res.Distance != 0 || res.WarmStarted {
+		t.Errorf("原图已被误分类时应返回距离 0 且不使用热启动, 实际 %+v", res)
+	}
+}
+
+func TestHSJAPooledEarlyStopping(t *testing.T) {
+	fmt.Println("=== 测试 HSJA 提前停止与共享预算 ===")
+	mathutils.SetSeed(3)
+	cfg := attack.HSJAConfig{MaxQueries: 2000, MaxIterations: 8, NumEvals: 50, InitEvals: 50, ClipMin: 0, ClipMax: 1, StopThreshold: 0.2}
+	// 样本 0 离边界 0.05，很快定论；样本 1 离边界 0.5，永远不会低于阈值
+	samples := []core.Sample{constSample(0, 0.45, 0), constSample(1, 0.0, 0), constSample(2, 0.4, 0)}
+	results := attack.NewHSJA(cfg).AttackPooled(samples, pixelModel{}, 1)
+
+	total := 0
+	for _, r := range results {
+		fmt.Printf("  样本 %d: 距离 %.4f, 查询 %d, 已定论 %v\n", r.SampleID, r.Distance, r.Queries, r.DecisionFinal)
+		total += r.Queries
+		if r.DecisionFinal && r.Distance >= cfg.StopThreshold {
+			t.Errorf("样本 %d: 已定论但距离 %.4f 不低于阈值", r.SampleID, r.Distance)
+		}
+	}
+	if total > len(samples)*cfg.MaxQueries {
+		t.Errorf("总查询 %d 超过共享预算 %d", total, len(samples)*cfg.MaxQueries)
+	}
+	if !results[0].DecisionFinal || results[0].Queries >= cfg.MaxQueries {
+		t.Errorf("样本 0 应提前定论并省下查询: %+v", results[0])
+	}
+	// 省下的查询分给了未定论的样本，它们用到了超过单个样本的预算
+	if results[1].DecisionFinal || results[1].Queries <= cfg.MaxQueries {
+		t.Errorf("样本 1 应分到额外预算且仍未定论: %+v", results[1])
+	}
+}
+
+func TestHSJAPooledWithoutThreshold(t *testing.T) {
+	fmt.Println("=== 测试 HSJA 共享预算 (无阈值时等价于逐个攻击) ===")
+	cfg := attack.HSJAConfig{MaxQueries: 500, NumEvals: 20, InitEvals: 20, ClipMin: 0, ClipMax: 1}
+	samples := []core.Sample{constSample(0, 0.45, 0), constSample(1, 0.2, 0)}
+
+	mathutils.SetSeed(5)
+	pooled := attack.NewHSJA(cfg).AttackPooled(samples, pixelModel{}, 1)
+	mathutils.SetSeed(5)
+	atk := attack.NewHSJA(cfg)
+	for i, s := range samples {
+		single := atk.Attack(s, pixelModel{})
+		if single.Distance != pooled[i].Distance || single.Queries != pooled[i].Queries || pooled[i].DecisionFinal {
+			t.Errorf("样本 %d: 逐个攻击 %+v 与共享预算 %+v 不一致", i, single, pooled[i])
+		}
+	}
+}
+
+func TestHSJACheckpoints(t *testing.T) {
+	fmt.Println("=== 测试 HSJA 查询预算检查点 ===")
+	mathutils.SetSeed(7)
+	checkpoints := []int{5, 200, 600, 5000}
+	cfg := attack.HSJAConfig{MaxQueries: 600, MaxIterations: 600, NumEvals: 20, InitEvals: 20, ClipMin: 0, ClipMax: 1, Checkpoints: checkpoints}
+	res := attack.NewHSJA(cfg).Attack(constSample(0, 0.3, 0), pixelModel{})
+	fmt.Printf("  检查点 %+v, 最终距离 %.4f, 查询 %d\n", res.Checkpoints, res.Distance, res.Queries)
+
+	if len(res.Checkpoints) != len(checkpoints) {
+		t.Fatalf("期望 %d 个检查点, 实际 %d", len(checkpoints), len(res.Checkpoints))
+	}
+	// 初始化加二分至少要 1 + 10 次查询，5 次以内还没有对抗样本
+	if !math.IsInf(res.Checkpoints[0].Distance, 1) {
+		t.Errorf("5 次查询内不应有距离, 实际 %.4f", res.Checkpoints[0].Distance)
+	}
+	for i := 1; i < len(checkpoints); i++ {
+		if res.Checkpoints[i].Queries != checkpoints[i] || res.Checkpoints[i].Distance > res.Checkpoints[i-1].Distance {
+			t.Errorf("检查点 %d 不符或距离变大: %+v", i, res.Checkpoints)
+		}
+	}
+	// 超过 MaxQueries 的检查点停在攻击结束时的距离
+	if res.Checkpoints[3].Distance != res.Distance {
+		t.Errorf("最后一个检查点期望等于最终距离 %.4f, 实际 %.4f", res.Distance, res.Checkpoints[3].Distance)
+	}
+
+	// 轮数上限先到时，之后的检查点都停在同一个距离上
+	mathutils.SetSeed(7)
+	cfg.MaxIterations = 2
+	capped := attack.NewHSJA(cfg).Attack(constSample(0, 0.3, 0), pixelModel{})
+	if capped.Queries >= 200 || capped.Checkpoints[1].Distance != capped.Checkpoints[3].Distance {
+		t.Errorf("2 轮上限下 200 次以后的检查点应相同: %+v (查询 %d)", capped.Checkpoints, capped.Queries)
+	}
+
+	// 检查点写进成绩单的 dist@ 列后可以读回并画曲线
+	path := filepath.Join(t.TempDir(), "sweep.csv")
+	results := []core.AttackResult{res, capped}
+	results[1].SampleID = 1
+	if err := eval.WriteResultsCSV(path, results, []bool{true, false}); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	loaded, members, err := eval.LoadResultsCSV(path)
+	if err != nil {
+		t.Fatalf("读回失败: %v", err)
+	}
+	curve, err := eval.EvaluateBudgets(loaded, members, eval.HigherIsMember, 0.5)
+	if err != nil {
+		t.Fatalf("预算曲线失败: %v", err)
+	}
+	if len(curve.Points) != 4 || curve.Points[0].Succeeded != 0 || curve.Points[3].Succeeded != 2 {
+		t.Errorf("预算曲线不符: %+v", curve.Points)
+	}
+}
+
+func TestLoadCifarBatch(t *testing.T) {
+	fmt.Println("=== 测试 CIFAR 二进制读取 ===")
+	path := filepath.Join(t.TempDir(), "batch.bin")
+	record := make([]byte, 1+core.FlattenedSize)
+	var data []byte
+	for label := 0; label < 3; label++ {
+		record[0] = byte(label)
+		record[1] = byte(label * 100)
+		data = append(data, record...)
+	}
+	os.WriteFile(path, data, 0o644)
+
+	samples, err := dataset.LoadCifarBatch(path, -1)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if len(samples) != 3 || samples[2].Label != 2 || samples[2].ID != 2 || samples[2].Data[0] != 200.0/255 || len(samples[2].Data) != core.FlattenedSize {
+		t.Errorf("读取结果不符: %d 条, 最后一条标签 %d", len(samples), samples[len(samples)-1].Label)
+	}
+	if limited, _ := dataset.LoadCifarBatch(path, 2); len(limited) != 2 {
+		t.Errorf("limit=2 期望 2 条, 实际 %d", len(limited))
+	}
+	os.WriteFile(path, data[:len(data)-1], 0o644)
+	if _, err := dataset.LoadCifarBatch(path, -1); err == nil {
+		t.Error("最后一条记录不完整时应当报错")
+	}
+}
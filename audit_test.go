@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"label-only-mia-go/pkg/audit"
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+)
+
+// 辅助函数：ID 为 from, from+step, ... 的 n 个样本 (配合 idAttacker，距离即 ID)
+func idSamples(from, step, n int) []core.Sample {
+	samples := make([]core.Sample, n)
+	for i := range samples {
+		samples[i] = core.Sample{ID: from + i*step}
+	}
+	return samples
+}
+
+func TestDatasetInference(t *testing.T) {
+	fmt.Println("=== 测试数据集推断 (顺序检验提前停止) ===")
+	suspect, unseen := idSamples(100, 1, 40), idSamples(0, 1, 40)
+
+	cfg := audit.DatasetInferenceConfig{Test: audit.MannWhitney, Sequential: true, BatchSize: 10}
+	res, err := audit.DatasetInference(idAttacker{}, pixelModel{}, suspect, unseen, cfg)
+	if err != nil {
+		t.Fatalf("推断失败: %v", err)
+	}
+	fmt.Print(res)
+	// 计划 4 次检验，有效显著性水平 0.01 / 4；第一批 10 对完全分开，z = 49.5 / √(100/12 · 21) = 3.742
+	if !res.Significant || !res.StoppedEarly || res.Looks != 1 || res.SuspectUsed != 10 || res.Alpha != 0.0025 {
+		t.Errorf("期望第一批即显著并提前停止: %+v", res)
+	}
+	if res.SuspectMean != 104.5 || res.UnseenMean != 4.5 {
+		t.Errorf("平均距离期望 104.5 / 4.5, 实际 %.4f / %.4f", res.SuspectMean, res.UnseenMean)
+	}
+}
+
+func TestDatasetInferenceSameDistribution(t *testing.T) {
+	fmt.Println("=== 测试数据集推断 (同分布不显著) ===")
+	// 可疑集取偶数 ID，未见集取奇数 ID：两组交错，没有差异
+	suspect, unseen := idSamples(0, 2, 20), idSamples(1, 2, 20)
+	for _, kind := range []audit.TestKind{audit.WelchT, audit.MannWhitney} {
+		res, err := audit.DatasetInference(idAttacker{}, pixelModel{}, suspect, unseen, audit.DatasetInferenceConfig{Test: kind, Workers: 2})
+		if err != nil {
+			t.Fatalf("推断失败: %v", err)
+		}
+		if res.Significant || res.Looks != 1 || res.StoppedEarly || res.Test.PValue < 0.5 {
+			t.Errorf("检验 %d: 同分布不应显著: %+v", kind, res)
+		}
+	}
+	if _, err := audit.DatasetInference(idAttacker{}, pixelModel{}, suspect[:1], unseen, audit.DatasetInferenceConfig{}); err == nil {
+		t.Error("可疑集不足 2 个样本时应当报错")
+	}
+}
+
+// 辅助模型：每个样本的边界距离由 dist 给出 (配合 tableAttacker)
+type distModel struct {
+	pixelModel
+	dist func(id int) float64
+}
+
+// 辅助攻击器：直接读取 distModel 中的距离，每个样本消耗 1 次查询
+type tableAttacker struct{}
+
+func (tableAttacker) Attack(s core.Sample, m core.Model) core.AttackResult {
+	return core.AttackResult{SampleID: s.ID, IsSuccess: true, Queries: 1, Distance: m.(*distModel).dist(s.ID)}
+}
+
+func TestVerifyUnlearningVerdicts(t *testing.T) {
+	fmt.Println("=== 测试遗忘验证的判定 ===")
+	// 遗忘集 ID 0..19，保留集 ID 100..119；遗忘前距离在 2 附近
+	sets := audit.UnlearningSets{Forget: idSamples(0, 1, 20), Retain: idSamples(100, 1, 20)}
+	before := &distModel{dist: func(id int) float64 { return 2 + float64(id%20)*0.02 }}
+	// 小抖动保证差值有方差
+	jitter := func(id int) float64 { return float64(id%3) * 0.005 }
+
+	cases := []struct {
+		name                         string
+		forget, retain               func(id int) float64 // 遗忘后相对遗忘前的距离
+		moved, kept, shifted, passed bool
+	}{
+		{"定向遗忘", func(id int) float64 { return 0.5 + jitter(id) }, func(id int) float64 { return jitter(id) },
+			true, true, true, true},
+		{"没有遗忘", func(id int) float64 { return jitter(id) - 0.005 }, func(id int) float64 { return jitter(id) },
+			false, true, true, false},
+		{"整体破坏", func(id int) float64 { return 0.5 + jitter(id) }, func(id int) float64 { return 0.6 + jitter(id) },
+			true, false, true, false},
+		// 保留集平均下降 0.25 (约 11%)，但各样本方向不一，配对检验不显著；只看检验会误判为“未变”
+		{"保留集大幅波动", func(id int) float64 { return 0.5 + jitter(id) }, func(id int) float64 { return []float64{1.8, -0.4, 0.4, -0.8}[id%4] },
+			true, false, false, false},
+	}
+
+	for _, c := range cases {
+		after := &distModel{dist: func(id int) float64 {
+			if id >= 100 {
+				return before.dist(id) - c.retain(id)
+			}
+			return before.dist(id) - c.forget(id)
+		}}
+		for _, kind := range []audit.TestKind{audit.WelchT, audit.MannWhitney} {
+			res, err := audit.VerifyUnlearning(tableAttacker{}, before, after, sets, audit.UnlearningConfig{Test: kind, Bootstrap: eval.BootstrapConfig{Iterations: 100}})
+			if err != nil {
+				t.Fatalf("%s: 验证失败: %v", c.name, err)
+			}
+			if res.ForgetMoved != c.moved || res.RetainKept != c.kept || res.RetainShifted != c.shifted || res.Passed != c.passed {
+				t.Errorf("%s (检验 %d): 期望 moved=%v kept=%v shifted=%v passed=%v, 实际 %v %v %v %v\n%s",
+					c.name, kind, c.moved, c.kept, c.shifted, c.passed, res.ForgetMoved, res.RetainKept, res.RetainShifted, res.Passed, res)
+			}
+			if res.Queries != 80 {
+				t.Errorf("%s: 期望 80 次查询, 实际 %d", c.name, res.Queries)
+			}
+		}
+	}
+}
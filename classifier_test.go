@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"label-only-mia-go/pkg/classifier"
+	"label-only-mia-go/pkg/core"
+)
+
+// 辅助函数：成员距离大且攻击更费查询，非成员相反；两类在距离上完全可分
+func classifierData() ([][]float64, []bool) {
+	var results []core.AttackResult
+	var members []bool
+	for i := 0; i < 40; i++ {
+		member := i%2 == 0
+		r := core.AttackResult{SampleID: i, IsSuccess: true, Distance: float64(i%10) * 0.01, Queries: 100 + i}
+		if member {
+			r.Distance += 0.5
+		}
+		results = append(results, r)
+		members = append(members, member)
+	}
+	return classifier.FeatureMatrix(classifier.FromResults(results)), members
+}
+
+func TestClassifierTrainAndCrossValidate(t *testing.T) {
+	fmt.Println("=== 测试多特征分类器 (训练、交叉验证与保存) ===")
+	x, y := classifierData()
+
+	for _, c := range []struct {
+		name  string
+		train classifier.Trainer
+	}{
+		{"logistic", classifier.LogisticTrainer(classifier.LogisticConfig{})},
+		{"stumps", classifier.StumpsTrainer(classifier.BoostConfig{})},
+	} {
+		name, train := c.name, c.train
+		cv, err := classifier.CrossValidate(x, y, 4, 1, train)
+		if err != nil {
+			t.Fatalf("%s: 交叉验证失败: %v", name, err)
+		}
+		fmt.Printf("  %s: 交叉验证 AUC %.4f ± %.4f\n", name, cv.MeanAUC, cv.StdAUC)
+		if cv.MeanAUC != 1 {
+			t.Errorf("%s: 完全可分的数据期望交叉验证 AUC 1, 实际 %.4f", name, cv.MeanAUC)
+		}
+
+		clf, err := train(x, y)
+		if err != nil {
+			t.Fatalf("%s: 训练失败: %v", name, err)
+		}
+		path := filepath.Join(t.TempDir(), name+".json")
+		if err := classifier.Save(path, clf); err != nil {
+			t.Fatalf("%s: 保存失败: %v", name, err)
+		}
+		loaded, err := classifier.Load(path)
+		if err != nil {
+			t.Fatalf("%s: 读取失败: %v", name, err)
+		}
+		for i, row := range x {
+			if loaded.PredictProba(row) != clf.PredictProba(row) {
+				t.Fatalf("%s: 样本 %d 读回后的概率不一致", name, i)
+			}
+		}
+	}
+}
+
+func TestClassifierRejectsBadInput(t *testing.T) {
+	fmt.Println("=== 测试多特征分类器 (空训练集与特征名不一致) ===")
+	if _, err := classifier.TrainLogistic(nil, nil, classifier.LogisticConfig{}); err == nil {
+		t.Error("空训练集训练逻辑回归应当报错")
+	}
+	if _, err := classifier.TrainStumps(nil, nil, classifier.BoostConfig{}); err == nil {
+		t.Error("空训练集训练树桩应当报错")
+	}
+	if _, err := classifier.TrainLogistic([][]float64{{1}, {1, 2}}, []bool{true, false}, classifier.LogisticConfig{}); err == nil {
+		t.Error("维数不一致的特征应当报错")
+	}
+
+	// 特征个数相同但名字 (顺序) 不同的模型文件不能直接使用
+	x, y := classifierData()
+	clf, _ := classifier.TrainLogistic(x, y, classifier.LogisticConfig{})
+	path := filepath.Join(t.TempDir(), "clf.json")
+	if err := classifier.Save(path, clf); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var saved map[string]any
+	json.Unmarshal(data, &saved)
+	names := saved["features"].([]any)
+	names[0], names[1] = names[1], names[0]
+	data, _ = json.Marshal(saved)
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := classifier.Load(path); err == nil {
+		t.Error("特征名与当前版本不一致时读取应当报错")
+	}
+}
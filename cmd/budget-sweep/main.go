@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"label-only-mia-go/pkg/attack"
+	"label-only-mia-go/pkg/audit"
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/dataset"
+	"label-only-mia-go/pkg/eval"
+	"label-only-mia-go/pkg/remote"
+)
+
+// budget-sweep: 对成员 / 非成员各 n 张 CIFAR 图片跑一次带预算检查点的 HSJA，
+// 导出带 dist@<查询数> 列的成绩单，并输出 AUC-查询预算曲线。
+// 导出的成绩单可以直接交给 mia-eval 画图、求置信区间。
+// 用法:
+//
+//	go run ./cmd/budget-sweep -url http://127.0.0.1:8000 -members data/data_batch_1.bin -nonmembers data/test_batch.bin -out sweep.csv
+//	go run ./cmd/budget-sweep -stdio "python3 serve_model.py" -budgets 100,1000,5000,20000
+//	go run ./cmd/mia-eval -in sweep.csv -budget-svg sweep.svg
+func main() {
+	members := flag.String("members", "data/data_batch_1.bin", "成员样本的 CIFAR 二进制文件 (训练集)")
+	nonMembers := flag.String("nonmembers", "data/test_batch.bin", "非成员样本的 CIFAR 二进制文件 (测试集)")
+	n := flag.Int("n", 100, "成员与非成员各取多少张")
+	url := flag.String("url", "", "HTTP 模型服务地址")
+	stdio := flag.String("stdio", "", "以子进程方式启动的模型命令 (按空格切分)")
+	budgets := flag.String("budgets", "100,1000,5000,20000", "逗号分隔的查询预算检查点，最大值即每个样本的查询上限")
+	numEvals := flag.Int("num-evals", 100, "HSJA 每轮梯度估计的采样次数")
+	workers := flag.Int("workers", 8, "并发攻击的 goroutine 数")
+	fpr := flag.Float64("fpr", 0.01, "曲线中 TPR 使用的假阳性率")
+	out := flag.String("out", "budget_sweep.csv", "导出带 dist@<查询数> 列的成绩单")
+	flag.Parse()
+
+	checkpoints, err := parseBudgets(*budgets)
+	if err != nil {
+		fail("解析查询预算失败", err)
+	}
+
+	var model core.Model
+	switch {
+	case *url != "":
+		model = remote.NewHTTPModel(remote.HTTPConfig{Endpoint: *url})
+	case *stdio != "":
+		fields := strings.Fields(*stdio)
+		stdioModel := remote.NewStdioModel(remote.StdioConfig{Command: fields[0], Args: fields[1:]})
+		defer stdioModel.Close()
+		model = stdioModel
+	default:
+		fail("缺少模型", fmt.Errorf("需要 -url 或 -stdio"))
+	}
+
+	m, err := dataset.LoadCifarBatch(*members, *n)
+	if err != nil {
+		fail("读取成员样本失败", err)
+	}
+	nm, err := dataset.LoadCifarBatch(*nonMembers, *n)
+	if err != nil {
+		fail("读取非成员样本失败", err)
+	}
+	samples := append(m, nm...)
+	isMember := make([]bool, len(samples))
+	for i := range m {
+		isMember[i] = true
+	}
+
+	// 迭代轮数不设上限 (每轮至少 1 次查询，MaxIterations = MaxQueries 等价于只受查询数限制)，
+	// 否则默认的 50 轮用完后，更大预算的检查点都会停在同一个距离上
+	maxQueries := checkpoints[len(checkpoints)-1]
+	hsja := attack.NewHSJA(attack.HSJAConfig{
+		MaxQueries:    maxQueries,
+		MaxIterations: maxQueries,
+		NumEvals:      *numEvals,
+		ClipMin:       0,
+		ClipMax:       1,
+		Checkpoints:   checkpoints,
+	})
+
+	fmt.Printf("🚀 预算扫描: %d 个成员 + %d 个非成员，检查点 %v\n", len(m), len(nm), checkpoints)
+	results := audit.AttackAll(hsja, model, samples, *workers)
+
+	if err := eval.WriteResultsCSV(*out, results, isMember); err != nil {
+		fail("导出失败", err)
+	}
+	fmt.Printf("💾 成绩单已保存至: %s\n", *out)
+
+	curve, err := eval.EvaluateBudgets(results, isMember, eval.HigherIsMember, *fpr)
+	if err != nil {
+		fail("预算曲线计算失败", err)
+	}
+	fmt.Println("📈 AUC-查询预算曲线:")
+	fmt.Print(curve)
+}
+
+// parseBudgets 解析逗号分隔的正整数预算并升序排列
+func parseBudgets(s string) ([]int, error) {
+	var budgets []int
+	for _, field := range strings.Split(s, ",") {
+		b, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if b <= 0 {
+			return nil, fmt.Errorf("查询预算 %d 必须为正数", b)
+		}
+		budgets = append(budgets, b)
+	}
+	sort.Ints(budgets)
+	return budgets, nil
+}
+
+func fail(msg string, err error) {
+	fmt.Fprintf(os.Stderr, "❌ %s: %v\n", msg, err)
+	os.Exit(1)
+}
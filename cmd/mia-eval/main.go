@@ -0,0 +1,384 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"label-only-mia-go/pkg/classifier"
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/dataset"
+	"label-only-mia-go/pkg/eval"
+)
+
+// mia-eval: 读取 LabelScan-Go 导出的审计成绩单，输出成员推理指标
+// 用法:
+//
+//	go run ./cmd/mia-eval -in LabelScan-Go/final_audit_score.csv
+//	# 在校准集上求阈值并保存，再应用到新的审计
+//	go run ./cmd/mia-eval -calib calib.csv -strategy fixed_fpr -fpr 0.01 -save-threshold thr.json -in audit.csv
+//	go run ./cmd/mia-eval -threshold thr.json -in fresh_audit.csv -out predictions.csv
+//	# 导出最脆弱样本的档案与缩略图
+//	go run ./cmd/mia-eval -threshold thr.json -in audit.csv -dossier top -members data/data_batch_1.bin -nonmembers data/test_batch.bin
+//	# 比较基线模型与防御后模型的泄露程度 (DeLong 检验 + 配对自助法)
+//	go run ./cmd/mia-eval -in baseline.csv -compare defended.csv
+//	# 在影子成绩单上训练多特征成员分类器，保存后应用到新的审计
+//	go run ./cmd/mia-eval -calib shadow.csv -classifier stumps -save-classifier clf.json -in audit.csv
+func main() {
+	in := flag.String("in", "final_audit_score.csv", "ExportAttackResults 导出的 CSV")
+	lower := flag.Bool("lower", false, "分数越小越像成员 (默认距离越大越像成员)")
+	calib := flag.String("calib", "", "校准用 CSV，shadow 策略下可用逗号分隔多个影子模型的结果")
+	strategy := flag.String("strategy", string(eval.StrategyMaxAccuracy), "校准策略: max_accuracy | fixed_fpr | shadow")
+	fpr := flag.Float64("fpr", 0.01, "fixed_fpr 策略的目标假阳性率")
+	saveThr := flag.String("save-threshold", "", "把校准好的阈值保存到该 JSON 文件")
+	loadThr := flag.String("threshold", "", "从 JSON 文件读取已校准的阈值")
+	out := flag.String("out", "", "导出带判定结果的 CSV")
+	perClass := flag.Bool("per-class", false, "按 OriginalLabel 分别校准阈值，并输出按类别的指标")
+	minPerClass := flag.Int("min-per-class", 20, "按类别校准时每类至少需要的样本数，不足的类别回退到全局阈值")
+	bootIters := flag.Int("bootstrap", 1000, "自助法重采样次数 (0 表示不输出置信区间)")
+	bootSeed := flag.Int64("seed", 42, "自助法随机种子")
+	confidence := flag.Float64("confidence", 0.95, "置信水平")
+	compare := flag.String("compare", "", "与另一份成绩单 (同一批样本，例如加了防御的模型) 比较泄露程度")
+	compareFPR := flag.Float64("compare-fpr", 0.01, "比较 TPR 时使用的假阳性率")
+	priors := flag.String("priors", "0.5,0.1,0.01", "逗号分隔的成员先验比例，输出各先验下的期望精确率 (空字符串表示不计算)")
+	minRecall := flag.Float64("min-recall", 0.1, "挑选最高精确率阈值时要求的最低召回率")
+	priorOut := flag.String("prior-out", "", "导出各先验下逐阈值的 precision / recall / PPV 表")
+	dpDelta := flag.Float64("dp-delta", 0, "给出该 δ 下的差分隐私 ε 经验下界 (0 表示不计算)")
+	dossier := flag.String("dossier", "", "应用阈值后，把最脆弱的样本导出为 <前缀>.json 与 <前缀>.csv")
+	topN := flag.Int("top", 50, "脆弱性档案中导出的样本数")
+	membersBin := flag.String("members", "", "审计用的成员 CIFAR 二进制文件，给脆弱性档案带上文件名并生成 <前缀>_thumbs/ 缩略图")
+	nonMembersBin := flag.String("nonmembers", "", "审计用的非成员 CIFAR 二进制文件 (与 -members 配合使用)")
+	budgetOut := flag.String("budget-out", "", "成绩单带 dist@<查询数> 列时，导出 AUC-查询预算曲线表 (CSV)")
+	budgetSVG := flag.String("budget-svg", "", "成绩单带 dist@<查询数> 列时，把 AUC-查询预算曲线画成 SVG")
+	clfKind := flag.String("classifier", "", "在 -calib 的影子成绩单上训练多特征成员分类器并用于审计结果: logistic | stumps")
+	cvFolds := flag.Int("cv-folds", 5, "训练分类器时交叉验证的折数")
+	saveClf := flag.String("save-classifier", "", "把训练好的分类器保存到该 JSON 文件")
+	loadClf := flag.String("load-classifier", "", "从 JSON 文件读取已训练的分类器并用于审计结果")
+	flag.Parse()
+
+	dir := eval.HigherIsMember
+	if *lower {
+		dir = eval.LowerIsMember
+	}
+
+	// 1. 准备阈值 (可选)
+	var thr eval.Judge
+	var err error
+	switch {
+	case *calib != "":
+		thr, err = calibrate(strings.Split(*calib, ","), eval.Strategy(*strategy), *fpr, dir, *perClass, *minPerClass)
+		if err == nil && *saveThr != "" {
+			if err = thr.Save(*saveThr); err == nil {
+				fmt.Printf("💾 阈值已保存至: %s\n", *saveThr)
+			}
+		}
+	case *loadThr != "" && *perClass:
+		thr, err = eval.LoadClassThresholds(*loadThr)
+	case *loadThr != "":
+		thr, err = eval.LoadThreshold(*loadThr)
+	}
+	if err != nil {
+		fail("阈值准备失败", err)
+	}
+	if *dossier != "" && thr == nil {
+		fail("无法导出脆弱性档案", fmt.Errorf("排名依赖校准后的成员分数，需要 -calib 或 -threshold 提供阈值"))
+	}
+
+	// 2. 评估审计结果
+	results, members, err := eval.LoadResultsCSV(*in)
+	if err != nil {
+		fail("读取失败", err)
+	}
+
+	boot := eval.BootstrapConfig{Iterations: *bootIters, Seed: *bootSeed, Confidence: *confidence}
+	report, err := eval.EvaluateWithCI(results, members, dir, boot)
+	if err != nil {
+		fail("评估失败", err)
+	}
+	fmt.Printf("📊 成员推理评估: %s\n", *in)
+	fmt.Print(report)
+
+	if *priors != "" {
+		var reports []*eval.PriorReport
+		for _, field := range strings.Split(*priors, ",") {
+			prior, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				fail("解析成员先验失败", err)
+			}
+			r, err := eval.PrecisionAtPriorWithCI(results, members, dir, prior, *minRecall, boot)
+			if err != nil {
+				fail("先验精确率计算失败", err)
+			}
+			reports = append(reports, r)
+			fmt.Print(r)
+		}
+		if *priorOut != "" {
+			if err := eval.WritePriorCSV(*priorOut, reports); err != nil {
+				fail("导出先验表失败", err)
+			}
+			fmt.Printf("💾 先验精确率表已保存至: %s\n", *priorOut)
+		}
+	}
+
+	if *dpDelta > 0 {
+		est, err := eval.EmpiricalEpsilonWithCI(results, members, dir, *dpDelta, *confidence, boot)
+		if err != nil {
+			fail("ε 估计失败", err)
+		}
+		fmt.Print(est)
+	}
+
+	if len(results) > 0 && len(results[0].Checkpoints) > 0 {
+		curve, err := eval.EvaluateBudgetsWithCI(results, members, dir, *fpr, boot)
+		if err != nil {
+			fail("预算曲线计算失败", err)
+		}
+		fmt.Println("📈 AUC-查询预算曲线:")
+		fmt.Print(curve)
+		if *budgetOut != "" {
+			if err := eval.WriteBudgetCSV(*budgetOut, curve); err != nil {
+				fail("导出预算曲线失败", err)
+			}
+			fmt.Printf("💾 预算曲线已保存至: %s\n", *budgetOut)
+		}
+		if *budgetSVG != "" {
+			if err := eval.WriteBudgetSVG(*budgetSVG, curve); err != nil {
+				fail("绘制预算曲线失败", err)
+			}
+			fmt.Printf("💾 预算曲线图已保存至: %s\n", *budgetSVG)
+		}
+	}
+
+	if *compare != "" {
+		other, otherMembers, err := eval.LoadResultsCSV(*compare)
+		if err != nil {
+			fail("读取对比文件失败", err)
+		}
+		a, b, pairedMembers, err := eval.PairResults(results, members, other, otherMembers)
+		if err != nil {
+			fail("对齐失败", err)
+		}
+		cmp, err := eval.Compare(a, b, pairedMembers, dir, *compareFPR, boot)
+		if err != nil {
+			fail("比较失败", err)
+		}
+		fmt.Printf("⚖️  泄露比较: A=%s  B=%s\n", *in, *compare)
+		fmt.Print(cmp)
+	}
+
+	if *perClass {
+		byClass, err := eval.EvaluateByClass(results, members, dir, boot)
+		if err != nil {
+			fail("按类别评估失败", err)
+		}
+		fmt.Println("📊 按类别评估 (AUC 由高到低):")
+		fmt.Print(eval.FormatClassReports(byClass))
+	}
+
+	if *clfKind != "" || *loadClf != "" {
+		if err := runClassifier(*clfKind, *loadClf, *saveClf, *calib, *cvFolds, *bootSeed, results, members); err != nil {
+			fail("成员分类器失败", err)
+		}
+	}
+
+	if thr == nil {
+		return
+	}
+
+	// 3. 应用阈值，给每个样本一个真正的 IsMember 判定
+	judged := thr.Apply(results)
+	correct := 0
+	for i, r := range judged {
+		if r.IsMember == members[i] {
+			correct++
+		}
+	}
+	fmt.Printf("🎯 阈值判定准确率 %.4f\n", float64(correct)/float64(len(judged)))
+
+	if *out != "" {
+		if err := eval.WriteResultsCSV(*out, judged, members); err != nil {
+			fail("导出失败", err)
+		}
+		fmt.Printf("💾 判定结果已保存至: %s\n", *out)
+	}
+
+	if *dossier != "" {
+		samples, sampleMembers, err := loadAuditSamples(*membersBin, *nonMembersBin, judged, members)
+		if err != nil {
+			fail("读取审计样本失败", err)
+		}
+		ranked, err := eval.RankVulnerability(judged, members, samples, sampleMembers)
+		if err != nil {
+			fail("脆弱性排名失败", err)
+		}
+		top := ranked[:min(*topN, len(ranked))]
+		if len(samples) > 0 {
+			if err := eval.WriteThumbnails(*dossier+"_thumbs", top, samples, sampleMembers); err != nil {
+				fail("生成缩略图失败", err)
+			}
+			fmt.Printf("🖼️  缩略图已保存至: %s_thumbs/\n", *dossier)
+		}
+		if err := eval.WriteRankingJSON(*dossier+".json", top); err != nil {
+			fail("导出脆弱性档案失败", err)
+		}
+		if err := eval.WriteRankingCSV(*dossier+".csv", top); err != nil {
+			fail("导出脆弱性档案失败", err)
+		}
+		fmt.Printf("💾 前 %d 个最脆弱样本已保存至: %s.json / %s.csv\n", len(top), *dossier, *dossier)
+	}
+}
+
+// loadAuditSamples 读取审计时用的成员 / 非成员批次文件，只读到成绩单中出现的最大 ID 为止。
+// 两个路径都为空时返回空切片 (档案中不带文件名和缩略图)。
+func loadAuditSamples(membersPath, nonMembersPath string, results []core.AttackResult, members []bool) ([]core.Sample, []bool, error) {
+	limits := map[bool]int{}
+	for i, r := range results {
+		limits[members[i]] = max(limits[members[i]], r.SampleID+1)
+	}
+
+	var samples []core.Sample
+	var sampleMembers []bool
+	for _, batch := range []struct {
+		path   string
+		member bool
+	}{{membersPath, true}, {nonMembersPath, false}} {
+		if batch.path == "" || limits[batch.member] == 0 {
+			continue
+		}
+		loaded, err := dataset.LoadCifarBatch(batch.path, limits[batch.member])
+		if err != nil {
+			return nil, nil, err
+		}
+		samples = append(samples, loaded...)
+		for range loaded {
+			sampleMembers = append(sampleMembers, batch.member)
+		}
+	}
+	return samples, sampleMembers, nil
+}
+
+// runClassifier 训练 (或读取) 多特征成员分类器，报告其在审计结果上的 AUC 与准确率。
+// 成绩单里只有距离、查询数与是否成功三项特征，鲁棒性特征需要在有模型的审计程序里用 classifier.Extractor 提取。
+func runClassifier(kind, loadPath, savePath, calib string, folds int, seed int64, results []core.AttackResult, members []bool) error {
+	var clf classifier.Classifier
+	if loadPath != "" {
+		loaded, err := classifier.Load(loadPath)
+		if err != nil {
+			return err
+		}
+		clf = loaded
+	} else {
+		var train classifier.Trainer
+		switch kind {
+		case "logistic":
+			train = classifier.LogisticTrainer(classifier.LogisticConfig{})
+		case "stumps":
+			train = classifier.StumpsTrainer(classifier.BoostConfig{})
+		default:
+			return fmt.Errorf("未知的分类器类型 %q", kind)
+		}
+		if calib == "" {
+			return fmt.Errorf("训练分类器需要 -calib 指定影子成绩单")
+		}
+
+		var shadowResults []core.AttackResult
+		var shadowMembers []bool
+		for _, p := range strings.Split(calib, ",") {
+			r, m, err := eval.LoadResultsCSV(p)
+			if err != nil {
+				return err
+			}
+			shadowResults = append(shadowResults, r...)
+			shadowMembers = append(shadowMembers, m...)
+		}
+		x := classifier.FeatureMatrix(classifier.FromResults(shadowResults))
+
+		cv, err := classifier.CrossValidate(x, shadowMembers, folds, seed, train)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🧠 分类器 %s: %d 折交叉验证 AUC %.4f ± %.4f\n", kind, folds, cv.MeanAUC, cv.StdAUC)
+
+		if clf, err = train(x, shadowMembers); err != nil {
+			return err
+		}
+		if savePath != "" {
+			if err := classifier.Save(savePath, clf); err != nil {
+				return err
+			}
+			fmt.Printf("💾 分类器已保存至: %s\n", savePath)
+		}
+	}
+
+	scored := classifier.ScoreResults(clf, results, classifier.FromResults(results))
+	scores := make([]float64, len(scored))
+	correct := 0
+	for i, r := range scored {
+		scores[i] = r.MembershipScore
+		if r.IsMember == members[i] {
+			correct++
+		}
+	}
+	auc := eval.AUC(eval.ROCFromScores(scores, members, eval.HigherIsMember))
+	fmt.Printf("🧠 分类器在审计结果上: AUC %.4f，准确率 %.4f\n", auc, float64(correct)/float64(len(scored)))
+	return nil
+}
+
+// calibrate 按策略从校准文件求阈值 (全局或按类别)
+func calibrate(paths []string, strategy eval.Strategy, fpr float64, dir eval.Direction, perClass bool, minPerClass int) (eval.Judge, error) {
+	var shadows []eval.ShadowSet
+	for _, p := range paths {
+		results, members, err := eval.LoadResultsCSV(p)
+		if err != nil {
+			return nil, err
+		}
+		shadows = append(shadows, eval.ShadowSet{Results: results, Members: members})
+	}
+
+	if perClass {
+		var results []core.AttackResult
+		var members []bool
+		for _, s := range shadows {
+			results = append(results, s.Results...)
+			members = append(members, s.Members...)
+		}
+		switch strategy {
+		case eval.StrategyFixedFPR:
+			return eval.CalibratePerClass(results, members, eval.FixedFPRCalibrator(fpr, dir), minPerClass)
+		case eval.StrategyMaxAccuracy, eval.StrategyShadow:
+			return eval.CalibratePerClass(results, members, eval.MaxAccuracyCalibrator(dir), minPerClass)
+		}
+		return nil, fmt.Errorf("未知的校准策略 %q", strategy)
+	}
+
+	switch strategy {
+	case eval.StrategyShadow:
+		return eval.CalibrateShadow(shadows, dir)
+	case eval.StrategyFixedFPR:
+		var nonMembers []core.AttackResult
+		for _, s := range shadows {
+			for i, r := range s.Results {
+				if !s.Members[i] {
+					nonMembers = append(nonMembers, r)
+				}
+			}
+		}
+		return eval.CalibrateFixedFPR(nonMembers, fpr, dir)
+	case eval.StrategyMaxAccuracy:
+		var results []core.AttackResult
+		var members []bool
+		for _, s := range shadows {
+			results = append(results, s.Results...)
+			members = append(members, s.Members...)
+		}
+		return eval.CalibrateMaxAccuracy(results, members, dir)
+	}
+	return nil, fmt.Errorf("未知的校准策略 %q", strategy)
+}
+
+func fail(msg string, err error) {
+	fmt.Fprintf(os.Stderr, "❌ %s: %v\n", msg, err)
+	os.Exit(1)
+}
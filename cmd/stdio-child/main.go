@@ -0,0 +1,55 @@
+// stdio-child 是子进程模型协议 (pkg/remote/STDIO_PROTOCOL.md) 的 Go 替身，
+// 供测试和联调使用：不需要 Python 环境就能验证 remote.StdioModel。
+//
+// 模型规则与 cmd/attack_test 的 SimpleModel 相同：第 0 个像素 > 0.5 判为 1，否则为 0。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/remote"
+)
+
+type pixelModel struct {
+	size      int
+	dieAfter  int // 处理这么多个请求后直接退出，用于测试父进程的重启逻辑
+	processed int
+}
+
+func (m *pixelModel) Predict(img core.Image) (int, error) {
+	if img[0] > 0.5 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m *pixelModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	if m.dieAfter > 0 && m.processed >= m.dieAfter {
+		os.Exit(3)
+	}
+	m.processed++
+
+	labels := make([]int, len(imgs))
+	for i, img := range imgs {
+		labels[i], _ = m.Predict(img)
+	}
+	return labels, nil
+}
+
+func (m *pixelModel) GetInputSize() int { return m.size }
+
+func main() {
+	size := flag.Int("size", core.FlattenedSize, "每张图的 float32 个数")
+	dieAfter := flag.Int("die-after", 0, "处理 N 个请求后异常退出 (0 表示不退出)")
+	flag.Parse()
+
+	model := &pixelModel{size: *size, dieAfter: *dieAfter}
+	if err := remote.ServeStdio(model, os.Stdin, os.Stdout); err != nil && err != io.ErrUnexpectedEOF {
+		fmt.Fprintf(os.Stderr, "stdio-child: %v\n", err)
+		os.Exit(1)
+	}
+}
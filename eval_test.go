@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"math"
+	"os"
+	"testing"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+)
+
+// 辅助函数：由距离列表构造攻击结果
+func resultsFromDistances(dists []float64) []core.AttackResult {
+	results := make([]core.AttackResult, len(dists))
+	for i, d := range dists {
+		results[i] = core.AttackResult{SampleID: i, IsSuccess: true, Distance: d}
+	}
+	return results
+}
+
+func TestAUCPerfectSeparation(t *testing.T) {
+	fmt.Println("=== 测试 AUC (完全可分) ===")
+	results := resultsFromDistances([]float64{0.9, 0.8, 0.7, 0.3, 0.2, 0.1})
+	members := []bool{true, true, true, false, false, false}
+
+	report, err := eval.Evaluate(results, members, eval.HigherIsMember)
+	if err != nil {
+		t.Fatalf("Evaluate 失败: %v", err)
+	}
+	fmt.Print(report)
+
+	if report.AUC != 1.0 || report.BalancedAccuracy != 1.0 || report.TPRAt1FPR != 1.0 {
+		t.Errorf("完全可分时期望 AUC/准确率/TPR 均为 1, 实际 %+v", report)
+	}
+
+	// 反转方向后应变为完全相反
+	flipped, _ := eval.Evaluate(results, members, eval.LowerIsMember)
+	if flipped.AUC != 0.0 {
+		t.Errorf("反向 AUC 期望 0, 实际 %.4f", flipped.AUC)
+	}
+}
+
+func TestAUCTies(t *testing.T) {
+	fmt.Println("=== 测试 AUC (相同分数) ===")
+	// 所有分数相同时，AUC 必须恰好为 0.5
+	results := resultsFromDistances([]float64{0.5, 0.5, 0.5, 0.5})
+	members := []bool{true, false, true, false}
+
+	curve := eval.ROC(results, members, eval.HigherIsMember)
+	if got := eval.AUC(curve); math.Abs(got-0.5) > 1e-12 {
+		t.Errorf("相同分数 AUC 期望 0.5, 实际 %.4f", got)
+	}
+}
+
+func TestAUCMatchesMannWhitney(t *testing.T) {
+	fmt.Println("=== 测试 AUC 与 Mann-Whitney U 一致 ===")
+	dists := []float64{0.4, 0.9, 0.2, 0.6, 0.6, 0.1, 0.8, 0.3}
+	members := []bool{true, true, false, true, false, false, true, false}
+
+	// 暴力计算 P(成员分数 > 非成员分数) + 0.5 P(相等)
+	var wins float64
+	var pairs int
+	for i := range dists {
+		for j := range dists {
+			if members[i] && !members[j] {
+				pairs++
+				if dists[i] > dists[j] {
+					wins++
+				} else if dists[i] == dists[j] {
+					wins += 0.5
+				}
+			}
+		}
+	}
+	want := wins / float64(pairs)
+
+	got := eval.AUC(eval.ROC(resultsFromDistances(dists), members, eval.HigherIsMember))
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("AUC 期望 %.4f, 实际 %.4f", want, got)
+	}
+}
+
+// 辅助函数：40 个样本，成员距离整体偏大但与非成员有重叠；每个样本带 3 个预算检查点
+func overlappingResults() ([]core.AttackResult, []bool) {
+	var results []core.AttackResult
+	var members []bool
+	for i := 0; i < 40; i++ {
+		member := i%2 == 0
+		d := float64(i%20) * 0.05
+		if member {
+			d += 0.3
+		}
+		results = append(results, core.AttackResult{
+			SampleID: i, IsSuccess: true, Distance: d,
+			Checkpoints: []core.Checkpoint{{Queries: 10, Distance: d + 0.5}, {Queries: 100, Distance: d + 0.1}, {Queries: 1000, Distance: d}},
+		})
+		members = append(members, member)
+	}
+	return results, members
+}
+
+func TestBootstrapIntervals(t *testing.T) {
+	fmt.Println("=== 测试自助法置信区间 (可复现且包含点估计) ===")
+	results, members := overlappingResults()
+	boot := eval.BootstrapConfig{Iterations: 200, Seed: 7}
+
+	report, err := eval.EvaluateWithCI(results, members, eval.HigherIsMember, boot)
+	if err != nil {
+		t.Fatalf("评估失败: %v", err)
+	}
+	fmt.Print(report)
+	ci := report.CI
+	if ci == nil || ci.AUC.Low > report.AUC || ci.AUC.High < report.AUC || ci.AUC.Low == ci.AUC.High {
+		t.Fatalf("AUC %.4f 的区间不合理: %+v", report.AUC, ci)
+	}
+	again, _ := eval.Bootstrap(results, members, eval.HigherIsMember, boot)
+	if *again != *ci {
+		t.Errorf("相同种子的区间应当一致: %+v vs %+v", again, ci)
+	}
+	if noCI, _ := eval.EvaluateWithCI(results, members, eval.HigherIsMember, eval.BootstrapConfig{}); noCI.CI != nil {
+		t.Error("重采样次数为 0 时不应输出置信区间")
+	}
+}
+
+func TestBootstrapIntervalsOnSummaries(t *testing.T) {
+	fmt.Println("=== 测试先验 PPV、经验 ε 与预算曲线的置信区间 ===")
+	results, members := overlappingResults()
+	boot := eval.BootstrapConfig{Iterations: 200, Seed: 7}
+
+	prior, err := eval.PrecisionAtPriorWithCI(results, members, eval.HigherIsMember, 0.1, 0.1, boot)
+	if err != nil {
+		t.Fatalf("先验 PPV 失败: %v", err)
+	}
+	fmt.Print(prior)
+	if prior.BestCI == nil || prior.BestCI.Low > prior.BestCI.High || prior.BestCI.High > 1 {
+		t.Errorf("最高 PPV 区间不合理: %+v", prior.BestCI)
+	}
+
+	eps, err := eval.EmpiricalEpsilonWithCI(results, members, eval.HigherIsMember, 0, 0.95, boot)
+	if err != nil {
+		t.Fatalf("经验 ε 失败: %v", err)
+	}
+	fmt.Print(eps)
+	if eps.EpsilonCI == nil || eps.EpsilonCI.Low < 0 || eps.EpsilonCI.Low > eps.EpsilonCI.High {
+		t.Errorf("ε 区间不合理: %+v", eps.EpsilonCI)
+	}
+
+	curve, err := eval.EvaluateBudgetsWithCI(results, members, eval.HigherIsMember, 0.1, boot)
+	if err != nil {
+		t.Fatalf("预算曲线失败: %v", err)
+	}
+	fmt.Print(curve)
+	for _, p := range curve.Points {
+		if p.AUCCI == nil || p.TPRCI == nil || p.AUCCI.Low > p.AUC || p.AUCCI.High < p.AUC {
+			t.Errorf("预算 %d: AUC %.4f 的区间不合理: %v", p.Queries, p.AUC, p.AUCCI)
+		}
+	}
+}
+
+func TestCompareDeLong(t *testing.T) {
+	fmt.Println("=== 测试 DeLong 检验 (手算参考值) ===")
+	members := []bool{true, true, true, false, false, false}
+	a := resultsFromDistances([]float64{0.9, 0.6, 0.4, 0.5, 0.3, 0.1})
+	b := resultsFromDistances([]float64{0.9, 0.8, 0.7, 0.3, 0.2, 0.1})
+
+	c, err := eval.Compare(a, b, members, eval.HigherIsMember, 0.5, eval.BootstrapConfig{Iterations: 200, Seed: 1})
+	if err != nil {
+		t.Fatalf("比较失败: %v", err)
+	}
+	fmt.Print(c)
+
+	// A: V10 = [1, 1, 2/3]，V01 = [2/3, 1, 1]，AUC 8/9，Var = (1/27)/3 + (1/27)/3 = 2/81
+	// B 完全可分，方差为 0；差值 1/9，z = (1/9) / (√2/9) = 1/√2
+	wantZ := 1 / math.Sqrt2
+	wantP := 2 * (1 - 0.5*math.Erfc(-wantZ/math.Sqrt2))
+	if math.Abs(c.AUCA-8.0/9) > 1e-12 || c.AUCB != 1 {
+		t.Errorf("AUC 期望 0.8889 / 1, 实际 %.4f / %.4f", c.AUCA, c.AUCB)
+	}
+	if math.Abs(c.AUCZ-wantZ) > 1e-12 || math.Abs(c.AUCPValue-wantP) > 1e-12 {
+		t.Errorf("期望 z=%.6f p=%.6f, 实际 z=%.6f p=%.6f", wantZ, wantP, c.AUCZ, c.AUCPValue)
+	}
+	se := math.Sqrt(2.0) / 9
+	if math.Abs(c.AUCDiffCI.High-c.AUCDiffCI.Low-2*1.959964*se) > 1e-5 {
+		t.Errorf("95%% 区间宽度期望 %.6f, 实际 %s", 2*1.959964*se, c.AUCDiffCI)
+	}
+}
+
+func TestComparePairedBootstrap(t *testing.T) {
+	fmt.Println("=== 测试配对自助法 (两份相同的结果) ===")
+	results, members := overlappingResults()
+	c, err := eval.Compare(results, results, members, eval.HigherIsMember, 0.1, eval.BootstrapConfig{Iterations: 200, Seed: 1})
+	if err != nil {
+		t.Fatalf("比较失败: %v", err)
+	}
+	// 配对重采样下两份结果永远相同，差值恒为 0
+	if c.TPRDiff != 0 || c.TPRDiffCI != (eval.Interval{}) || c.TPRPValue != 1 || c.AUCDiff != 0 || c.AUCPValue != 1 {
+		t.Errorf("相同结果的比较应无差异: %+v", c)
+	}
+}
+
+func TestPairResults(t *testing.T) {
+	fmt.Println("=== 测试成绩单对齐 (成员与非成员 ID 重复) ===")
+	a := []core.AttackResult{{SampleID: 0, Distance: 1}, {SampleID: 0, Distance: 2}, {SampleID: 1, Distance: 3}}
+	aMembers := []bool{true, false, true}
+	b := []core.AttackResult{{SampleID: 1, Distance: 30}, {SampleID: 0, Distance: 20}, {SampleID: 0, Distance: 10}}
+	bMembers := []bool{true, false, true}
+
+	pa, pb, members, err := eval.PairResults(a, aMembers, b, bMembers)
+	if err != nil {
+		t.Fatalf("对齐失败: %v", err)
+	}
+	for i := range pa {
+		if pb[i].Distance != 10*pa[i].Distance || members[i] != aMembers[i] {
+			t.Errorf("第 %d 对没有对齐: %+v / %+v", i, pa[i], pb[i])
+		}
+	}
+	if _, _, _, err := eval.PairResults(a, aMembers, b[:2], bMembers[:2]); err == nil {
+		t.Error("样本缺失时应当报错")
+	}
+}
+
+func TestEmpiricalEpsilonClopperPearson(t *testing.T) {
+	fmt.Println("=== 测试经验 ε (Clopper-Pearson 界与 Beta 分位数参考值) ===")
+	// 10 个成员与 10 个非成员完全可分：最优阈值下 TPR = 10/10，FPR = 0/10
+	dists := make([]float64, 20)
+	members := make([]bool, 20)
+	for i := range dists {
+		members[i] = i < 10
+		dists[i] = float64(20-i) * 0.01
+	}
+	results := resultsFromDistances(dists)
+
+	// 10/10 的 Clopper-Pearson 下界 = Beta(α/2; 10, 1) 分位数 = (α/2)^(1/10)
+	// 95%: 0.025^0.1 = 0.691503；90%: 0.05^0.1 = 0.741134 (与常见二项置信区间表一致)
+	for _, c := range []struct{ confidence, delta, lower float64 }{
+		{0.95, 0, 0.691503},
+		{0.90, 0, 0.741134},
+		{0.95, 0.1, 0.691503},
+	} {
+		est, err := eval.EmpiricalEpsilon(results, members, eval.HigherIsMember, c.delta, c.confidence)
+		if err != nil {
+			t.Fatalf("计算失败: %v", err)
+		}
+		want := math.Log((c.lower - c.delta) / (1 - c.lower))
+		fmt.Printf("  置信度 %.2f δ=%g: ε = %.4f\n", c.confidence, c.delta, est.Epsilon)
+		if math.Abs(est.TPRLower-c.lower) > 1e-6 || math.Abs(est.FPRUpper-(1-c.lower)) > 1e-6 {
+			t.Errorf("置信度 %.2f: 期望 TPR 下界 %.6f / FPR 上界 %.6f, 实际 %.6f / %.6f",
+				c.confidence, c.lower, 1-c.lower, est.TPRLower, est.FPRUpper)
+		}
+		if math.Abs(est.Epsilon-want) > 1e-5 {
+			t.Errorf("置信度 %.2f δ=%g: 期望 ε %.6f, 实际 %.6f", c.confidence, c.delta, want, est.Epsilon)
+		}
+	}
+
+	// 分数完全相同时攻击没有区分能力，ε 下界为 0
+	ties := resultsFromDistances(make([]float64, 20))
+	if est, _ := eval.EmpiricalEpsilon(ties, members, eval.HigherIsMember, 0, 0.95); est.Epsilon != 0 {
+		t.Errorf("无区分能力时期望 ε = 0, 实际 %.4f", est.Epsilon)
+	}
+}
+
+func TestPrecisionAtPrior(t *testing.T) {
+	fmt.Println("=== 测试成员先验下的期望精确率 (手算 PPV) ===")
+	results := resultsFromDistances([]float64{0.9, 0.8, 0.7, 0.6, 0.5, 0.4})
+	members := []bool{true, true, false, true, false, false}
+
+	// 召回率 >= 0.9 时只有阈值 0.6 及以下可选，其中 0.6 (TPR 1, FPR 1/3) 的 PPV 最高
+	// PPV = π / (π + (1-π)/3)：π=0.5 时 0.75 (与平衡审计集的精确率一致)，π=0.01 时 0.01/0.34
+	for _, c := range []struct{ prior, ppv float64 }{{0.5, 0.75}, {0.01, 0.01 / 0.34}} {
+		r, err := eval.PrecisionAtPrior(results, members, eval.HigherIsMember, c.prior, 0.9)
+		if err != nil {
+			t.Fatalf("计算失败: %v", err)
+		}
+		fmt.Print(r)
+		if !r.HasBest || r.Best.Threshold != 0.6 || math.Abs(r.Best.PPV-c.ppv) > 1e-12 || r.Best.Precision != 0.75 {
+			t.Errorf("先验 %g: 期望阈值 0.6、PPV %.6f、精确率 0.75, 实际 %+v", c.prior, c.ppv, r.Best)
+		}
+	}
+
+	// 召回率要求较低时，FPR 为 0 的阈值 PPV 为 1，与先验无关
+	if r, _ := eval.PrecisionAtPrior(results, members, eval.HigherIsMember, 0.01, 0.5); r.Best.PPV != 1 || r.Best.Threshold != 0.8 {
+		t.Errorf("期望阈值 0.8 处 PPV 1, 实际 %+v", r.Best)
+	}
+	if r, _ := eval.PrecisionAtPrior(results, members, eval.HigherIsMember, 0.01, 1.1); r.HasBest {
+		t.Error("召回率要求无法满足时不应有最优阈值")
+	}
+	if _, err := eval.PrecisionAtPrior(results, members, eval.HigherIsMember, 1, 0.5); err == nil {
+		t.Error("先验为 1 时应当报错")
+	}
+}
+
+func TestWelchAndMannWhitney(t *testing.T) {
+	fmt.Println("=== 测试 Welch t 检验与 Mann-Whitney U 检验 (参考值) ===")
+	// 方差相等、样本数相同：t = -2，df = 8，单侧 p = P(T8 > 2) = 0.040258
+	w, err := eval.WelchTTest([]float64{1, 2, 3, 4, 5}, []float64{3, 4, 5, 6, 7}, eval.Less)
+	if err != nil {
+		t.Fatalf("检验失败: %v", err)
+	}
+	if math.Abs(w.Statistic+2) > 1e-12 || math.Abs(w.DF-8) > 1e-12 || math.Abs(w.PValue-0.040258) > 1e-6 {
+		t.Errorf("期望 t=-2 df=8 p=0.040258, 实际 %+v", w)
+	}
+	if two, _ := eval.WelchTTest([]float64{1, 2, 3, 4, 5}, []float64{3, 4, 5, 6, 7}, eval.TwoSided); math.Abs(two.PValue-0.080516) > 1e-6 {
+		t.Errorf("双侧 p 期望 0.080516, 实际 %.6f", two.PValue)
+	}
+
+	// 方差不等：t = -17/√(0.5 + 100/3) = -2.922648，Welch-Satterthwaite df = 2.060218，单侧 p = 0.048218
+	w, _ = eval.WelchTTest([]float64{1, 2, 3, 4, 5}, []float64{10, 20, 30}, eval.Less)
+	if math.Abs(w.Statistic+2.922648) > 1e-6 || math.Abs(w.DF-2.060218) > 1e-6 || math.Abs(w.PValue-0.048218) > 1e-6 {
+		t.Errorf("期望 t=-2.922648 df=2.060218 p=0.048218, 实际 %+v", w)
+	}
+
+	// 完全分开的两组：U = 0，μ = 12.5，σ = √(25/12 · 11)，z = -12/σ = -2.506718，p = Φ(z) = 0.006093
+	u, err := eval.MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, eval.Less)
+	if err != nil {
+		t.Fatalf("检验失败: %v", err)
+	}
+	if math.Abs(u.Statistic+2.506718) > 1e-6 || math.Abs(u.PValue-0.006093) > 1e-6 {
+		t.Errorf("期望 z=-2.506718 p=0.006093, 实际 %+v", u)
+	}
+	// 只看秩：攻击失败的 +Inf 不影响结果
+	inf, _ := eval.MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, math.Inf(1)}, eval.Less)
+	if inf != u {
+		t.Errorf("+Inf 距离不应改变秩检验: %+v vs %+v", inf, u)
+	}
+	// 所有值相同时没有任何证据
+	if tie, _ := eval.MannWhitneyU([]float64{1, 1}, []float64{1, 1}, eval.Greater); tie.PValue != 1 {
+		t.Errorf("全部相同期望 p=1, 实际 %.4f", tie.PValue)
+	}
+}
+
+func TestPairedTests(t *testing.T) {
+	fmt.Println("=== 测试配对 t 检验与 Wilcoxon 符号秩检验 (参考值) ===")
+	before := []float64{1, 2, 3, 4, 5}
+	after := []float64{0.5, 1.8, 2.1, 3.9, 4.0}
+
+	// 差值 [-0.5, -0.2, -0.9, -0.1, -1.0]：均值 -0.54，标准差 √0.163，t = -2.990783，df = 4，单侧 p = 0.020153
+	p, err := eval.PairedTTest(after, before, eval.Less)
+	if err != nil {
+		t.Fatalf("检验失败: %v", err)
+	}
+	if math.Abs(p.Statistic+2.990783) > 1e-6 || p.DF != 4 || math.Abs(p.PValue-0.020153) > 1e-6 {
+		t.Errorf("期望 t=-2.990783 df=4 p=0.020153, 实际 %+v", p)
+	}
+
+	// 差值全为负：T+ = 0，μ = 7.5，σ = √13.75，z = -7/σ = -1.887760，p = 0.029529
+	w, err := eval.WilcoxonSignedRank(after, before, eval.Less)
+	if err != nil {
+		t.Fatalf("检验失败: %v", err)
+	}
+	if math.Abs(w.Statistic+1.887760) > 1e-6 || math.Abs(w.PValue-0.029529) > 1e-6 {
+		t.Errorf("期望 z=-1.887760 p=0.029529, 实际 %+v", w)
+	}
+
+	// 同样的数据不配对时，组间差异淹没在组内差异里
+	if u, _ := eval.WelchTTest(after, before, eval.Less); u.PValue < 0.2 {
+		t.Errorf("不配对的 Welch 检验不应显著, 实际 p=%.4f", u.PValue)
+	}
+	if same, _ := eval.WilcoxonSignedRank(before, before, eval.TwoSided); same.PValue != 1 {
+		t.Errorf("差值全为 0 时期望 p=1, 实际 %.4f", same.PValue)
+	}
+	if _, err := eval.PairedTTest(before, after[:4], eval.Less); err == nil {
+		t.Error("长度不一致时应当报错")
+	}
+}
+
+func TestRankVulnerabilityDuplicateIDs(t *testing.T) {
+	fmt.Println("=== 测试脆弱性排名 (成员与非成员 ID 重复) ===")
+	// 成员 #0 与非成员 #0 来自不同的批次文件
+	results := []core.AttackResult{
+		{SampleID: 0, IsSuccess: true, Distance: 0.9, MembershipScore: 0.9},
+		{SampleID: 0, IsSuccess: true, Distance: 0.1, MembershipScore: 0.2},
+	}
+	members := []bool{false, true}
+	samples := []core.Sample{constSample(0, 1, 0), constSample(0, 0, 0)}
+	samples[0].Filename, samples[1].Filename = "train_#0", "test_#0"
+	sampleMembers := []bool{true, false}
+
+	ranked, err := eval.RankVulnerability(results, members, samples, sampleMembers)
+	if err != nil {
+		t.Fatalf("排名失败: %v", err)
+	}
+	if ranked[0].IsMember || ranked[0].Filename != "test_#0" || ranked[1].Filename != "train_#0" {
+		t.Errorf("文件名对应错误: %+v", ranked)
+	}
+
+	dir := t.TempDir()
+	if err := eval.WriteThumbnails(dir, ranked, samples, sampleMembers); err != nil {
+		t.Fatalf("生成缩略图失败: %v", err)
+	}
+	// 非成员 #0 是全黑图片，成员 #0 是全白图片
+	for i, want := range []uint32{0, 0xffff} {
+		file, err := os.Open(ranked[i].Thumbnail)
+		if err != nil {
+			t.Fatalf("第 %d 名没有缩略图: %v", i+1, err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("缩略图解码失败: %v", err)
+		}
+		if r, _, _, _ := img.At(0, 0).RGBA(); r != want {
+			t.Errorf("第 %d 名缩略图取错了样本: 像素 %d, 期望 %d", i+1, r, want)
+		}
+	}
+
+	if _, err := eval.RankVulnerability(results, members, samples, sampleMembers[:1]); err == nil {
+		t.Error("样本与成员真值长度不一致时应当报错")
+	}
+	if _, err := eval.RankVulnerability(results, members, append(samples, samples[0]), append(sampleMembers, true)); err == nil {
+		t.Error("重复的 (ID, 成员) 应当报错")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/lira"
+)
+
+// 辅助函数：样本 #0 有 IN/OUT 两组参考距离，样本 #1 只有 OUT
+func liraStore() *lira.StatsStore {
+	store := lira.NewStatsStore()
+	store.Add("#0", 1.0, true)
+	store.Add("#0", 1.2, true)
+	store.Add("#0", 0.4, false)
+	store.Add("#0", 0.6, false)
+	store.Add("#1", 0.3, false)
+	store.Add("#1", 0.7, false)
+	return store
+}
+
+func TestLiRAOnlineAndOffline(t *testing.T) {
+	fmt.Println("=== 测试 LiRA 打分 (在线 / 离线) ===")
+	sc := lira.NewScorer(liraStore())
+
+	// 在线: IN ~ N(1.1, 0.1)，OUT ~ N(0.5, 0.1)
+	// d = 1.1 时 log LR = 0 - (-0.5 * 6²) = 18
+	if p, _ := sc.Score("#0", 1.1); math.Abs(p-1/(1+math.Exp(-18))) > 1e-12 {
+		t.Errorf("d=1.1: 期望 %.12f, 实际 %.12f", 1/(1+math.Exp(-18)), p)
+	}
+	// 两个方差相同的高斯在中点处似然相等
+	if p, _ := sc.Score("#0", 0.8); math.Abs(p-0.5) > 1e-12 {
+		t.Errorf("d=0.8: 期望 0.5, 实际 %.12f", p)
+	}
+	// 离线: OUT ~ N(0.5, 0.2)，Φ((0.7 - 0.5) / 0.2) = Φ(1) = 0.841345
+	if p, _ := sc.Score("#1", 0.7); math.Abs(p-0.841345) > 1e-6 {
+		t.Errorf("离线 d=0.7: 期望 0.841345, 实际 %.6f", p)
+	}
+	// 离线：攻击失败比任何 OUT 距离都远；在线：参考模型上从未失败时，失败本身不区分 IN/OUT
+	if p, _ := sc.Score("#1", math.Inf(1)); p != 1 {
+		t.Errorf("离线攻击失败: 期望 1, 实际 %.4f", p)
+	}
+	if p, _ := sc.Score("#0", math.Inf(1)); p != 0.5 {
+		t.Errorf("在线攻击失败: 期望 0.5, 实际 %.4f", p)
+	}
+	if _, ok := sc.Score("#9", 1); ok {
+		t.Error("没有参考数据的样本不应能打分")
+	}
+}
+
+func TestLiRAReferenceFailures(t *testing.T) {
+	fmt.Println("=== 测试 LiRA 打分 (参考模型上的攻击失败计入似然) ===")
+	store := liraStore()
+	store.AddFailure("#0", true) // 鲁棒的 IN 参考模型上找不到对抗样本
+	sc := lira.NewScorer(store)
+
+	// p_in = 1.5 / 4 = 0.375，p_out = 0.5 / 3：失败时 LR = 2.25
+	if p, _ := sc.Score("#0", math.Inf(1)); math.Abs(p-2.25/3.25) > 1e-12 {
+		t.Errorf("攻击失败: 期望 %.6f, 实际 %.6f", 2.25/3.25, p)
+	}
+	// 中点处两个高斯相等，只剩成功率之比 0.625 / (5/6) = 0.75
+	if p, _ := sc.Score("#0", 0.8); math.Abs(p-0.75/1.75) > 1e-12 {
+		t.Errorf("d=0.8: 期望 %.6f, 实际 %.6f", 0.75/1.75, p)
+	}
+
+	// 离线：OUT 中一半失败，成功的距离只占下半部分
+	store.AddFailure("#1", false)
+	store.AddFailure("#1", false)
+	if p, _ := sc.Score("#1", 0.5); math.Abs(p-0.25) > 1e-12 {
+		t.Errorf("离线 d=0.5: 期望 0.25, 实际 %.6f", p)
+	}
+	if p, _ := sc.Score("#1", math.Inf(1)); p != 0.75 {
+		t.Errorf("离线攻击失败: 期望 0.75, 实际 %.6f", p)
+	}
+}
+
+func TestLiRAGlobalVariance(t *testing.T) {
+	fmt.Println("=== 测试 LiRA 打分 (合并方差，批量与单个打分一致) ===")
+	sc := lira.NewScorer(liraStore())
+	sc.GlobalVariance = true
+
+	// OUT 合并标准差 = sqrt((0.02 + 0.08) / 4) = 0.158114
+	want := 0.5 * math.Erfc(-(0.2/math.Sqrt(0.025))/math.Sqrt2)
+	if p, _ := sc.Score("#1", 0.7); math.Abs(p-want) > 1e-12 {
+		t.Errorf("期望 %.6f, 实际 %.6f", want, p)
+	}
+
+	samples := []core.Sample{{ID: 0}, {ID: 1}, {ID: 2}}
+	results := []core.AttackResult{
+		{SampleID: 0, IsSuccess: true, Distance: 0.9},
+		{SampleID: 1, IsSuccess: true, Distance: 0.7},
+		{SampleID: 2, IsSuccess: true, Distance: 0.5},
+	}
+	scored := sc.ScoreResults(results, samples)
+	for i, r := range scored[:2] {
+		p, _ := sc.Score(lira.SampleKey(samples[i]), results[i].Distance)
+		if r.MembershipScore != p || r.IsMember != (p > 0.5) {
+			t.Errorf("样本 %d: 批量打分 %.6f 与单个打分 %.6f 不一致", i, r.MembershipScore, p)
+		}
+	}
+	if scored[2].MembershipScore != 0 {
+		t.Error("没有参考数据的样本应保持原样")
+	}
+}
+
+func TestLiRAStoreRoundTrip(t *testing.T) {
+	fmt.Println("=== 测试 LiRA 统计库读写 ===")
+	path := filepath.Join(t.TempDir(), "stats.json")
+	store := liraStore()
+	store.AddFailure("#0", true)
+	if err := store.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	loaded, err := lira.LoadStatsStore(path)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	st, ok := loaded.Get("#0")
+	if !ok || fmt.Sprint(st.In, st.Out) != "[1 1.2] [0.4 0.6]" || st.InFailed != 1 || st.OutFailed != 0 {
+		t.Errorf("读回的统计不符: %+v", st)
+	}
+}
+
+// 辅助攻击器：直接返回样本 ID 作为距离
+type idAttacker struct{}
+
+func (idAttacker) Attack(s core.Sample, m core.Model) core.AttackResult {
+	return core.AttackResult{SampleID: s.ID, IsSuccess: true, Distance: float64(s.ID)}
+}
+
+// 辅助攻击器：ID 为 failID 的样本攻击失败，其余同 idAttacker
+type failingAttacker struct {
+	idAttacker
+	failID int
+}
+
+func (a failingAttacker) Attack(s core.Sample, m core.Model) core.AttackResult {
+	r := a.idAttacker.Attack(s, m)
+	r.IsSuccess = s.ID != a.failID
+	return r
+}
+
+func TestLiRACollect(t *testing.T) {
+	fmt.Println("=== 测试 LiRA 参考模型收集 ===")
+	reg := lira.NewRegistry()
+	if err := reg.Register("ref-a", pixelModel{}, []string{"#1"}); err != nil {
+		t.Fatal(err)
+	}
+	reg.Register("ref-b", pixelModel{}, nil)
+	if err := reg.Register("ref-a", pixelModel{}, nil); err == nil {
+		t.Error("重复登记同名参考模型应当报错")
+	}
+
+	store := lira.NewStatsStore()
+	lira.Collect(failingAttacker{failID: 1}, reg, []core.Sample{{ID: 1}, {ID: 2}}, store, 2)
+
+	st1, _ := store.Get("#1")
+	st2, _ := store.Get("#2")
+	if st1.InFailed != 1 || st1.OutFailed != 1 || len(st1.In)+len(st1.Out) != 0 || len(st2.In) != 0 || len(st2.Out) != 2 {
+		t.Errorf("IN/OUT 划分或失败计数不符: #1 %+v, #2 %+v", st1, st2)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"label-only-mia-go/pkg/attack"
+	"label-only-mia-go/pkg/audit"
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/dataset"
+	"label-only-mia-go/pkg/eval"
+	"label-only-mia-go/pkg/mathutils"
+	"label-only-mia-go/pkg/models"
+)
+
+// trainable 参考模型的公共形态
+type trainable interface {
+	core.Model
+	Train(samples []core.Sample) error
+}
+
+// 辅助函数：生成 CIFAR 形状 (3x32x32，[0, 1]) 的合成数据。
+// 每类一个随机原型，样本 = 0.6 * 原型 + 0.4 * 均匀噪声；同一 seed 得到同一批样本。
+func syntheticCifar(seed int64, perClass int) []core.Sample {
+	rng := rand.New(rand.NewSource(seed))
+	protos := make([]core.Image, 10)
+	for c := range protos {
+		protos[c] = make(core.Image, core.FlattenedSize)
+		for i := range protos[c] {
+			protos[c][i] = rng.Float32()
+		}
+	}
+
+	var samples []core.Sample
+	for n := 0; n < perClass; n++ {
+		for c, p := range protos {
+			img := make(core.Image, core.FlattenedSize)
+			for i := range img {
+				img[i] = 0.6*p[i] + 0.4*rng.Float32()
+			}
+			samples = append(samples, core.Sample{ID: len(samples), Data: img, Label: c})
+		}
+	}
+	return samples
+}
+
+// 辅助函数：模型在样本上的准确率
+func accuracy(t *testing.T, m core.Model, samples []core.Sample) float64 {
+	t.Helper()
+	correct := 0
+	for _, s := range samples {
+		label, err := m.Predict(s.Data)
+		if err != nil {
+			t.Fatalf("Predict 失败: %v", err)
+		}
+		if label == s.Label {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(samples))
+}
+
+func TestReferenceModelsDeterministic(t *testing.T) {
+	fmt.Println("=== 测试参考模型 (可训练、同种子可复现) ===")
+	train := syntheticCifar(1, 5)
+	test := syntheticCifar(2, 2)
+
+	builders := map[string]func() trainable{
+		"knn":     func() trainable { return models.NewKNN(models.KNNConfig{K: 3}) },
+		"1-nn":    func() trainable { return models.NewKNN(models.KNNConfig{K: -2}) }, // 负数退回默认的 1
+		"softmax": func() trainable { return models.NewSoftmax(models.SoftmaxConfig{Epochs: 5, Seed: 7}) },
+		"mlp":     func() trainable { return models.NewMLP(models.MLPConfig{Hidden: 16, Epochs: 5, Seed: 7}) },
+	}
+	for name, build := range builders {
+		a, b := build(), build()
+		if err := a.Train(train); err != nil {
+			t.Fatalf("%s 训练失败: %v", name, err)
+		}
+		if err := b.Train(train); err != nil {
+			t.Fatalf("%s 第二次训练失败: %v", name, err)
+		}
+
+		la, err := a.PredictBatch(imagesOf(test))
+		if err != nil {
+			t.Fatalf("%s 预测失败: %v", name, err)
+		}
+		lb, err := b.PredictBatch(imagesOf(test))
+		if err != nil {
+			t.Fatalf("%s 预测失败: %v", name, err)
+		}
+		if fmt.Sprint(la) != fmt.Sprint(lb) {
+			t.Errorf("%s: 同一种子训练出的模型预测不一致", name)
+		}
+
+		acc := accuracy(t, a, train)
+		fmt.Printf("  %s 训练集准确率 %.2f\n", name, acc)
+		if acc < 0.5 {
+			t.Errorf("%s: 训练集准确率 %.2f 过低", name, acc)
+		}
+	}
+}
+
+// 辅助函数：把样本写成 CIFAR-10 二进制 fixture 再用 dataset.LoadCifarBatch 读回
+func cifarFixture(t *testing.T, name string, samples []core.Sample) []core.Sample {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := dataset.WriteCifarBatch(path, samples); err != nil {
+		t.Fatalf("写出 fixture 失败: %v", err)
+	}
+	loaded, err := dataset.LoadCifarBatch(path, -1)
+	if err != nil {
+		t.Fatalf("读取 fixture 失败: %v", err)
+	}
+	if len(loaded) != len(samples) {
+		t.Fatalf("写出 %d 条，读回 %d 条", len(samples), len(loaded))
+	}
+	return loaded
+}
+
+func TestEndToEndAuditKNN(t *testing.T) {
+	fmt.Println("=== 端到端审计 (从 CIFAR 二进制文件训练 1-NN 目标模型 + HSJA + 评估) ===")
+	members := cifarFixture(t, "data_batch_1.bin", syntheticCifar(1, 3))  // 30 个训练样本，全部作为成员审计
+	nonMembers := cifarFixture(t, "test_batch.bin", syntheticCifar(3, 3)) // 同分布、未参与训练
+	if members[7].Label != 7 || members[7].Filename == "" {
+		t.Errorf("读回的样本不符: 标签 %d, 文件名 %q", members[7].Label, members[7].Filename)
+	}
+
+	model := models.NewKNN(models.KNNConfig{})
+	if err := model.Train(members); err != nil {
+		t.Fatalf("训练失败: %v", err)
+	}
+	if acc := accuracy(t, model, members); acc != 1 {
+		t.Errorf("1-NN 在训练集上的准确率应为 1, 实际 %.2f", acc)
+	}
+
+	samples := append(append([]core.Sample{}, members...), nonMembers...)
+	run := func() []core.AttackResult {
+		mathutils.SetSeed(42)
+		atk := attack.NewHSJA(attack.HSJAConfig{MaxQueries: 300, MaxIterations: 10, NumEvals: 20, ClipMin: 0, ClipMax: 1})
+		return audit.AttackAll(atk, model, samples, 1) // 多个 worker 共享全局随机源，结果依赖调度顺序
+	}
+	results := run()
+	if fmt.Sprint(results) != fmt.Sprint(run()) {
+		t.Error("同一种子、单个 worker 的两次审计结果应完全一致")
+	}
+
+	truth := make([]bool, len(samples))
+	for i := range members {
+		truth[i] = true
+	}
+	report, err := eval.Evaluate(results, truth, eval.HigherIsMember)
+	if err != nil {
+		t.Fatalf("评估失败: %v", err)
+	}
+	fmt.Print(report)
+	if report.AUC <= 0.5 {
+		t.Errorf("记忆型模型应当泄露成员信息: AUC = %.4f", report.AUC)
+	}
+}
+
+// 辅助函数：取出样本图片
+func imagesOf(samples []core.Sample) []core.Image {
+	imgs := make([]core.Image, len(samples))
+	for i, s := range samples {
+		imgs[i] = s.Data
+	}
+	return imgs
+}
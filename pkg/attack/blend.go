@@ -0,0 +1,162 @@
+package attack
+
+import (
+	"sort"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// NeighborPolicy 决定与哪张“异类”参考图片做混合
+type NeighborPolicy int
+
+const (
+	NeighborRandom    NeighborPolicy = iota // 随机挑一张其他类别的参考图
+	NeighborNearest                         // 按 L2 距离最近的其他类别参考图
+	NeighborPrototype                       // 最近的其他类别原型 (类内均值图)
+)
+
+// BlendConfig 配置混合基线攻击参数
+type BlendConfig struct {
+	References    []core.Sample  // 参考样本池 (训练集或公开参考集)
+	Policy        NeighborPolicy // 邻居选择策略
+	SearchSteps   int            // 二分次数 (默认 10)
+	MaxCandidates int            // 端点未被模型判为异类时最多换几张参考图 (默认 3)
+	ClipMin       float32        // 0.0
+	ClipMax       float32        // 1.0
+}
+
+// Blend “向异类混合”基线攻击器
+// 取一张其他类别的参考图，在样本与它的连线上二分，直到标签翻转，
+// 把翻转点到原图的 L2 距离作为 Distance。
+// 只需要十几次查询，用作 HSJA (上千次查询) 的廉价对照组。
+type Blend struct {
+	config     BlendConfig
+	prototypes map[int]core.Image // 各类别的均值图，仅 NeighborPrototype 使用
+}
+
+// NewBlend 创建混合基线攻击器
+func NewBlend(cfg BlendConfig) *Blend {
+	if cfg.SearchSteps == 0 {
+		cfg.SearchSteps = 10
+	}
+	if cfg.MaxCandidates == 0 {
+		cfg.MaxCandidates = 3
+	}
+
+	atk := &Blend{config: cfg}
+	if cfg.Policy == NeighborPrototype {
+		atk.prototypes = classPrototypes(cfg.References)
+	}
+	return atk
+}
+
+// Attack 实现 core.Attacker 接口
+func (atk *Blend) Attack(sample core.Sample, model core.Model) core.AttackResult {
+	queries := 0
+	predictFunc := func(img []float32) int {
+		queries++
+		l, _ := model.Predict(img)
+		return l
+	}
+
+	original := sample.Data
+	targetLabel := sample.Label
+
+	// 1. 按策略排好候选端点，找到第一张确实被模型判为异类的
+	var endpoint []float32
+	for i, candidate := range atk.candidates(sample) {
+		if i >= atk.config.MaxCandidates {
+			break
+		}
+		if predictFunc(candidate) != targetLabel {
+			endpoint = candidate
+			break
+		}
+	}
+	if endpoint == nil {
+		return core.AttackResult{
+			SampleID: sample.ID, OriginalLabel: targetLabel, FinalLabel: targetLabel,
+			IsSuccess: false, Queries: queries, Distance: 0.0, IsMember: false,
+		}
+	}
+
+	// 2. 沿连线二分找到翻转点
+	xAdv := boundarySearch(original, endpoint, targetLabel, atk.config.SearchSteps,
+		atk.config.ClipMin, atk.config.ClipMax, predictFunc)
+
+	finalLabel := predictFunc(xAdv)
+
+	return core.AttackResult{
+		SampleID:      sample.ID,
+		OriginalLabel: targetLabel,
+		FinalLabel:    finalLabel,
+		IsSuccess:     finalLabel != targetLabel,
+		Queries:       queries,
+		Distance:      mathutils.L2Distance(original, xAdv),
+		IsMember:      false,
+	}
+}
+
+// candidates 按邻居策略返回排好序的异类端点
+func (atk *Blend) candidates(sample core.Sample) [][]float32 {
+	var pool [][]float32
+	if atk.config.Policy == NeighborPrototype {
+		// 按标签顺序遍历，距离相同的原型在排序后保持固定的先后
+		labels := make([]int, 0, len(atk.prototypes))
+		for label := range atk.prototypes {
+			labels = append(labels, label)
+		}
+		sort.Ints(labels)
+		for _, label := range labels {
+			if label != sample.Label {
+				pool = append(pool, atk.prototypes[label])
+			}
+		}
+	} else {
+		for _, ref := range atk.config.References {
+			if ref.Label != sample.Label {
+				pool = append(pool, ref.Data)
+			}
+		}
+	}
+
+	if atk.config.Policy == NeighborRandom {
+		shuffled := make([][]float32, len(pool))
+		for i, j := range mathutils.RandPerm(len(pool)) {
+			shuffled[i] = pool[j]
+		}
+		return shuffled
+	}
+
+	// Nearest / Prototype：按 L2 距离由近到远
+	dists := make([]float64, len(pool))
+	for i, p := range pool {
+		dists[i] = mathutils.L2Distance(sample.Data, p)
+	}
+	order := make([]int, len(pool))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return dists[order[a]] < dists[order[b]] })
+
+	sorted := make([][]float32, len(pool))
+	for i, j := range order {
+		sorted[i] = pool[j]
+	}
+	return sorted
+}
+
+// classPrototypes 计算每个类别的均值图
+func classPrototypes(refs []core.Sample) map[int]core.Image {
+	groups := make(map[int][][]float32)
+	for _, ref := range refs {
+		groups[ref.Label] = append(groups[ref.Label], ref.Data)
+	}
+
+	prototypes := make(map[int]core.Image, len(groups))
+	for label, imgs := range groups {
+		prototypes[label] = mathutils.MeanVector(imgs)
+	}
+	return prototypes
+}
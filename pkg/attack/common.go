@@ -0,0 +1,53 @@
+package attack
+
+import (
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// 各攻击器共用的决策边界工具函数。
+// predict 均为调用方封装好的“带计数”预测函数，查询次数由调用方统计。
+
+// binarySearchSteps HSJA 与进化攻击每次贴近边界时的二分次数
+const binarySearchSteps = 10
+
+// uniformInit 用均匀噪声撒点寻找初始对抗样本
+// 原图本身已被误分类时直接返回原图；找不到时返回 nil。
+func uniformInit(original []float32, label, evals int, clipMin, clipMax float32, predict func([]float32) int) []float32 {
+	if predict(original) != label {
+		return original
+	}
+	return noiseInit(original, label, evals, clipMin, clipMax, predict)
+}
+
+// noiseInit 只做均匀噪声撒点，不检查原图；找不到时返回 nil
+func noiseInit(original []float32, label, evals int, clipMin, clipMax float32, predict func([]float32) int) []float32 {
+	inputSize := len(original)
+	for i := 0; i < evals; i++ {
+		noise := mathutils.GenUniform(inputSize, float64(clipMin), float64(clipMax))
+		if predict(noise) != label {
+			return noise
+		}
+	}
+	return nil
+}
+
+// boundarySearch 在原图与对抗样本的连线上二分 steps 次，返回最靠近原图的对抗点
+func boundarySearch(original, adversarial []float32, label, steps int, clipMin, clipMax float32, predict func([]float32) int) []float32 {
+	low := 0.0
+	high := 1.0
+	boundaryPoint := adversarial
+
+	for i := 0; i < steps; i++ {
+		mid := (low + high) / 2.0
+		candidate := mathutils.Interpolate(original, adversarial, float32(mid))
+		candidate = mathutils.Clip(candidate, clipMin, clipMax)
+
+		if predict(candidate) != label {
+			high = mid
+			boundaryPoint = candidate
+		} else {
+			low = mid
+		}
+	}
+	return boundaryPoint
+}
@@ -0,0 +1,192 @@
+package attack
+
+import (
+	"math"
+	"sort"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/imaging"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// EvolutionaryConfig 配置进化攻击参数
+type EvolutionaryConfig struct {
+	MaxQueries    int     // 最大查询次数限制 (默认 10000)
+	MaxIterations int     // 最大迭代轮数 (默认 2*MaxQueries)；距离没有缩短的候选不会发起查询
+	InitEvals     int     // 初始化时的采样次数 (默认 100)
+	ReducedSide   int     // 低维搜索空间的边长，扰动在 3 x side x side 上采样 (默认 15)
+	CoordFraction float64 // 每轮扰动的坐标比例 k/m (默认 0.05，即论文中的 m/20)
+	Mu            float64 // 向原图收缩的初始步长 μ (默认 0.01)
+	SigmaScale    float64 // 噪声标准差 σ 相对当前距离的比例 (默认 0.01)
+	CC            float64 // 进化路径学习率 c_c (默认 0.01)
+	CCov          float64 // 对角协方差学习率 c_cov (默认 0.001)
+	ClipMin       float32 // 0.0
+	ClipMax       float32 // 1.0
+}
+
+// Evolutionary 进化决策攻击器 (Dong et al., 2019)
+// (1+1)-ES：每轮从当前最优对抗样本出发，在低维空间按自适应对角协方差采样一个候选，
+// 只有“仍然对抗且更靠近原图”时才替换父代，并据此更新协方差。
+// 与 HSJA 的蒙特卡洛梯度估计相比，它在边界高度弯曲、梯度符号噪声大时表现不同。
+type Evolutionary struct {
+	config EvolutionaryConfig
+}
+
+// NewEvolutionary 创建进化攻击器
+func NewEvolutionary(cfg EvolutionaryConfig) *Evolutionary {
+	if cfg.MaxQueries == 0 {
+		cfg.MaxQueries = 10000
+	}
+	if cfg.MaxIterations == 0 {
+		cfg.MaxIterations = 2 * cfg.MaxQueries
+	}
+	if cfg.InitEvals == 0 {
+		cfg.InitEvals = 100
+	}
+	if cfg.ReducedSide == 0 {
+		cfg.ReducedSide = 15
+	}
+	if cfg.CoordFraction == 0 {
+		cfg.CoordFraction = 0.05
+	}
+	if cfg.Mu == 0 {
+		cfg.Mu = 0.01
+	}
+	if cfg.SigmaScale == 0 {
+		cfg.SigmaScale = 0.01
+	}
+	if cfg.CC == 0 {
+		cfg.CC = 0.01
+	}
+	if cfg.CCov == 0 {
+		cfg.CCov = 0.001
+	}
+	return &Evolutionary{config: cfg}
+}
+
+// successWindow 统计成功率的滑动窗口长度 (1/5 成功法则)
+const successWindow = 30
+
+// Attack 实现 core.Attacker 接口
+func (atk *Evolutionary) Attack(sample core.Sample, model core.Model) core.AttackResult {
+	queries := 0
+	predictFunc := func(img []float32) int {
+		queries++
+		l, _ := model.Predict(img)
+		return l
+	}
+
+	original := sample.Data
+	targetLabel := sample.Label
+	cfg := atk.config
+
+	// 1. 初始化并二分贴近边界
+	xAdv := uniformInit(original, targetLabel, cfg.InitEvals, cfg.ClipMin, cfg.ClipMax, predictFunc)
+	if xAdv == nil {
+		return core.AttackResult{
+			SampleID: sample.ID, OriginalLabel: targetLabel, FinalLabel: targetLabel,
+			IsSuccess: false, Queries: queries, Distance: 0.0, IsMember: false,
+		}
+	}
+	xAdv = boundarySearch(original, xAdv, targetLabel, binarySearchSteps, cfg.ClipMin, cfg.ClipMax, predictFunc)
+	dist := mathutils.L2Distance(original, xAdv)
+
+	// 2. 低维搜索空间：只有 CIFAR 尺寸的输入才降维，其余输入直接在原空间搜索
+	m := len(original)
+	upsample := func(z []float32) []float32 { return z }
+	if len(original) == core.FlattenedSize {
+		side := cfg.ReducedSide
+		m = core.ImgChannels * side * side
+		upsample = func(z []float32) []float32 {
+			return imaging.Resize(z, core.ImgChannels, side, side, core.ImgHeight, core.ImgWidth)
+		}
+	}
+	k := max(int(float64(m)*cfg.CoordFraction), 1)
+
+	diagCov := mathutils.NewVector(m, 1) // 对角协方差 c_ii
+	evoPath := make([]float64, m)        // 进化路径 p_c
+	mu := cfg.Mu
+	successes := make([]bool, 0, successWindow)
+
+	// 3. (1+1)-ES 主循环
+	for iter := 0; iter < cfg.MaxIterations && queries < cfg.MaxQueries && dist > 0; iter++ {
+		sigma := cfg.SigmaScale * dist
+
+		// A. 按 c_ii 加权选出 k 个坐标，在其上采样 z ~ N(0, σ² C)
+		coords := weightedSample(diagCov, k)
+		noise := mathutils.GenGaussian(k, 0, sigma)
+		z := make([]float32, m)
+		for i, idx := range coords {
+			z[idx] = noise[i] * float32(math.Sqrt(float64(diagCov[idx])))
+		}
+
+		// B. 候选 = 父代 + 放大后的噪声 + μ (原图 - 父代)
+		bias := mathutils.VectorScale(mathutils.VectorSub(original, xAdv), float32(mu))
+		candidate := mathutils.VectorAdd(mathutils.VectorAdd(xAdv, upsample(z)), bias)
+		candidate = mathutils.Clip(candidate, cfg.ClipMin, cfg.ClipMax)
+
+		newDist := mathutils.L2Distance(original, candidate)
+		success := false
+		if newDist < dist && predictFunc(candidate) != targetLabel {
+			success = true
+			xAdv, dist = candidate, newDist
+
+			// C. 更新进化路径与对角协方差
+			decay := 1 - cfg.CC
+			gain := math.Sqrt(cfg.CC * (2 - cfg.CC))
+			for i := range evoPath {
+				evoPath[i] = decay*evoPath[i] + gain*float64(z[i])/sigma
+				c := (1-cfg.CCov)*float64(diagCov[i]) + cfg.CCov*evoPath[i]*evoPath[i]
+				diagCov[i] = float32(c)
+			}
+		}
+
+		// D. 1/5 成功法则调整 μ
+		if len(successes) == successWindow {
+			successes = successes[1:]
+		}
+		successes = append(successes, success)
+		if len(successes) == successWindow {
+			rate := 0.0
+			for _, s := range successes {
+				if s {
+					rate++
+				}
+			}
+			mu *= math.Exp(rate/successWindow - 0.2)
+		}
+	}
+
+	finalLabel := predictFunc(xAdv)
+
+	return core.AttackResult{
+		SampleID:      sample.ID,
+		OriginalLabel: targetLabel,
+		FinalLabel:    finalLabel,
+		IsSuccess:     finalLabel != targetLabel,
+		Queries:       queries,
+		Distance:      dist,
+		IsMember:      false,
+	}
+}
+
+// weightedSample 按权重不放回地抽取 k 个下标 (Efraimidis-Spirakis: key = u^(1/w))
+func weightedSample(weights []float32, k int) []int {
+	u := mathutils.GenUniform(len(weights), 0, 1)
+	keys := make([]float64, len(weights))
+	idx := make([]int, len(weights))
+	for i, w := range weights {
+		idx[i] = i
+		if w <= 0 {
+			keys[i] = -1
+			continue
+		}
+		keys[i] = math.Pow(float64(u[i]), 1/float64(w))
+	}
+
+	sort.Slice(idx, func(a, b int) bool { return keys[idx[a]] > keys[idx[b]] })
+	if k > len(idx) {
+		k = len(idx)
+	}
+	return idx[:k]
+}
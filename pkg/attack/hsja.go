@@ -1,9 +1,9 @@
 package attack
 
 import (
-	"math"
 	"label-only-mia-go/pkg/core"
 	"label-only-mia-go/pkg/mathutils"
+	"math"
 )
 
 // HSJAConfig 配置攻击参数
@@ -14,6 +14,20 @@ type HSJAConfig struct {
 	InitEvals     int     // 初始化时的采样次数 (默认 100)
 	ClipMin       float32 // 0.0
 	ClipMax       float32 // 1.0
+
+	WarmStart           *WarmStartCache // 热启动缓存 (可选，多个 HSJA 实例可共享同一个)
+	WarmStartCandidates int             // 热启动时最多尝试的历史方向数 (默认 5)
+
+	// StopThreshold 成员判定的距离阈值 (0 表示不提前停止)。
+	// 攻击过程中距离只会变小，一旦低于阈值判定就不会再变，此时直接停止；
+	// 省下的查询可由 AttackPooled 分给尚未定论的样本。
+	StopThreshold float64
+
+	// Checkpoints 查询预算检查点 (升序，可选)。一次攻击中记录每个预算内达到的最优距离，
+	// 用于画 AUC-查询预算曲线。攻击在 MaxQueries 或 MaxIterations (默认 50 轮) 先到者处停止，
+	// 之后的检查点都停在最终距离：扫描大预算时 MaxQueries 应不小于最大的检查点，
+	// MaxIterations 也要相应调大 (例如设为 MaxQueries，见 cmd/budget-sweep)。
+	Checkpoints []int
 }
 
 // HSJA 攻击器结构体
@@ -23,133 +37,202 @@ type HSJA struct {
 
 // NewHSJA 创建攻击器
 func NewHSJA(cfg HSJAConfig) *HSJA {
-	if cfg.NumEvals == 0 { cfg.NumEvals = 100 }
-	if cfg.MaxIterations == 0 { cfg.MaxIterations = 50 }
-	if cfg.InitEvals == 0 { cfg.InitEvals = 100 }
+	if cfg.NumEvals == 0 {
+		cfg.NumEvals = 100
+	}
+	if cfg.MaxIterations == 0 {
+		cfg.MaxIterations = 50
+	}
+	if cfg.InitEvals == 0 {
+		cfg.InitEvals = 100
+	}
+	if cfg.WarmStartCandidates == 0 {
+		cfg.WarmStartCandidates = 5
+	}
 	return &HSJA{config: cfg}
 }
 
+// hsjaRun 单个样本的攻击进度，提前停止模式下可以追加预算继续优化
+type hsjaRun struct {
+	sample      core.Sample
+	model       core.Model
+	queries     int
+	xAdv        []float32 // 当前最优的边界点 (初始化失败时为 nil)
+	dist        float64
+	iter        int
+	warmStarted bool
+	trace       []core.Checkpoint // 每轮迭代后的 (累计查询数, 最优距离)
+}
+
+// predict 带计数的预测函数
+func (r *hsjaRun) predict(img []float32) int {
+	r.queries++
+	l, _ := r.model.Predict(img)
+	return l
+}
+
 // Attack 实现 core.Attacker 接口
 func (atk *HSJA) Attack(sample core.Sample, model core.Model) core.AttackResult {
-	// 记录查询次数
-	queries := 0
-	
-	// 封装一个带计数的预测函数
-	predictFunc := func(img []float32) int {
-		queries++
-		l, _ := model.Predict(img)
-		return l
+	run := atk.start(sample, model)
+	if run.xAdv != nil {
+		atk.refine(run, atk.config.MaxQueries, atk.config.MaxIterations)
 	}
+	return atk.finish(run)
+}
 
+// start 初始化并二分到边界
+func (atk *HSJA) start(sample core.Sample, model core.Model) *hsjaRun {
+	run := &hsjaRun{sample: sample, model: model}
 	original := sample.Data
 	targetLabel := sample.Label
 
 	// 1. 初始化：寻找初始对抗样本
-	xAdv := atk.initialize(original, targetLabel, predictFunc)
-	
+	// 原图已被误分类时距离为 0，不需要热启动；
+	// 否则优先尝试热启动缓存中的历史方向，失败再退回均匀噪声撒点
+	var xAdv []float32
+	if run.predict(original) != targetLabel {
+		xAdv = original
+	} else {
+		xAdv, run.warmStarted = atk.warmStart(original, targetLabel, run.predict)
+		if xAdv == nil {
+			xAdv = noiseInit(original, targetLabel, atk.config.InitEvals, atk.config.ClipMin, atk.config.ClipMax, run.predict)
+		}
+	}
+
 	// 如果无法初始化（找不到任何对抗样本），则攻击失败
 	if xAdv == nil {
-		return core.AttackResult{
-			SampleID: sample.ID, OriginalLabel: targetLabel, FinalLabel: targetLabel,
-			IsSuccess: false, Queries: queries, Distance: 0.0, IsMember: false, // 距离无法计算
-		}
+		return run
 	}
 
 	// 2. 二分查找：找到决策边界
-	xAdv = atk.binarySearch(original, xAdv, targetLabel, predictFunc)
-
-	// 3. 迭代优化
+	run.xAdv = boundarySearch(original, xAdv, targetLabel, binarySearchSteps, atk.config.ClipMin, atk.config.ClipMax, run.predict)
 	// 计算初始 L2 距离 (注意: L2Distance 返回 float64)
-	dist := mathutils.L2Distance(original, xAdv)
+	run.dist = mathutils.L2Distance(original, run.xAdv)
+	run.record()
+	return run
+}
 
-	for i := 0; i < atk.config.MaxIterations; i++ {
+// refine 迭代优化，直到查询数达到 budget、迭代数达到 maxIter 或判定已定
+func (atk *HSJA) refine(run *hsjaRun, budget, maxIter int) {
+	original := run.sample.Data
+	targetLabel := run.sample.Label
+
+	for ; run.iter < maxIter; run.iter++ {
 		// 检查查询次数限制
-		if queries >= atk.config.MaxQueries {
+		if run.queries >= budget {
+			break
+		}
+		// 距离只会继续变小，低于阈值后成员判定不会再变
+		if atk.settled(run) {
 			break
 		}
 
 		// A. 梯度估计
-		delta := atk.computeDelta(float32(dist), i)
-		grad := atk.approximateGradient(xAdv, targetLabel, delta, predictFunc)
+		delta := atk.computeDelta(float32(run.dist), run.iter)
+		grad := atk.approximateGradient(run.xAdv, targetLabel, delta, run.predict)
 
 		// B. 几何级数步进 (Geometric Progression)
-		stepSize := atk.computeStepSize(float32(dist), i)
-		
+		stepSize := atk.computeStepSize(float32(run.dist), run.iter)
+
 		// x_new = x_adv + step_size * grad
 		// 修正：使用 VectorScale 和 VectorAdd
 		stepVec := mathutils.VectorScale(grad, stepSize)
-		xNew := mathutils.VectorAdd(xAdv, stepVec)
-		
+		xNew := mathutils.VectorAdd(run.xAdv, stepVec)
+
 		// C. 投影与裁剪
 		// 投影回合法像素范围 (Box Constraint)
 		xNew = mathutils.Clip(xNew, atk.config.ClipMin, atk.config.ClipMax)
-		
+
 		// D. 再次二分查找，确保贴紧边界
-		xNew = atk.binarySearch(original, xNew, targetLabel, predictFunc)
+		xNew = boundarySearch(original, xNew, targetLabel, binarySearchSteps, atk.config.ClipMin, atk.config.ClipMax, run.predict)
 
 		// E. 更新最优解
 		newDist := mathutils.L2Distance(original, xNew)
-		if newDist < dist {
-			dist = newDist
-			xAdv = xNew
+		if newDist < run.dist {
+			run.dist = newDist
+			run.xAdv = xNew
+		}
+		run.record()
+	}
+}
+
+// record 记录当前的查询数与最优距离
+func (r *hsjaRun) record() {
+	r.trace = append(r.trace, core.Checkpoint{Queries: r.queries, Distance: r.dist})
+}
+
+// checkpoints 由轨迹求每个预算内的最优距离：取查询数不超过预算的最后一个轨迹点
+func (atk *HSJA) checkpoints(run *hsjaRun) []core.Checkpoint {
+	if len(atk.config.Checkpoints) == 0 {
+		return nil
+	}
+
+	out := make([]core.Checkpoint, len(atk.config.Checkpoints))
+	for i, budget := range atk.config.Checkpoints {
+		out[i] = core.Checkpoint{Queries: budget, Distance: math.Inf(1)}
+		for _, p := range run.trace {
+			if p.Queries > budget {
+				break
+			}
+			out[i].Distance = p.Distance
+		}
+	}
+	return out
+}
+
+// settled 提前停止模式下，当前距离上界是否已低于阈值
+func (atk *HSJA) settled(run *hsjaRun) bool {
+	return atk.config.StopThreshold > 0 && run.xAdv != nil && run.dist < atk.config.StopThreshold
+}
+
+// finish 确认最终标签并生成攻击结果
+func (atk *HSJA) finish(run *hsjaRun) core.AttackResult {
+	sample := run.sample
+	if run.xAdv == nil {
+		return core.AttackResult{
+			SampleID: sample.ID, OriginalLabel: sample.Label, FinalLabel: sample.Label,
+			IsSuccess: false, Queries: run.queries, Distance: 0.0, IsMember: false, // 距离无法计算
+			WarmStarted: run.warmStarted, Checkpoints: atk.checkpoints(run),
 		}
 	}
 
 	// 获取最终标签
-	finalLabel := predictFunc(xAdv)
+	finalLabel := run.predict(run.xAdv)
+
+	// 把成功的方向留给后续同类样本
+	if atk.config.WarmStart != nil && finalLabel != sample.Label {
+		atk.config.WarmStart.Store(sample.Label, sample.Data, run.xAdv)
+	}
 
 	return core.AttackResult{
 		SampleID:      sample.ID,
-		OriginalLabel: targetLabel,
+		OriginalLabel: sample.Label,
 		FinalLabel:    finalLabel,
-		IsSuccess:     finalLabel != targetLabel,
-		Queries:       queries,
-		Distance:      dist,
+		IsSuccess:     finalLabel != sample.Label,
+		Queries:       run.queries,
+		Distance:      run.dist,
 		IsMember:      false, // 具体的 Member 判定逻辑通常在 CSV 分析阶段或根据 Threshold 判定
+		WarmStarted:   run.warmStarted,
+		DecisionFinal: finalLabel != sample.Label && atk.settled(run),
+		Checkpoints:   atk.checkpoints(run),
 	}
 }
 
-// initialize 寻找初始对抗样本
-func (atk *HSJA) initialize(original []float32, label int, predict func([]float32) int) []float32 {
-	if predict(original) != label {
-		return original
+// warmStart 用缓存中嵌入最接近的历史方向初始化
+// 每个方向按历史边界距离的 2 倍走一步，只花 1 次查询；随后的二分查找会把它拉回边界。
+func (atk *HSJA) warmStart(original []float32, label int, predict func([]float32) int) ([]float32, bool) {
+	if atk.config.WarmStart == nil {
+		return nil, false
 	}
 
-	inputSize := len(original)
-	for i := 0; i < atk.config.InitEvals; i++ {
-		// 修正：假设 GenUniform 在 noise.go 中
-		noise := mathutils.GenUniform(inputSize, float64(atk.config.ClipMin), float64(atk.config.ClipMax))
-		
-		if predict(noise) != label {
-			return noise
-		}
-	}
-	return nil
-}
-
-// binarySearch 二分查找边界
-func (atk *HSJA) binarySearch(original, adversarial []float32, targetLabel int, predict func([]float32) int) []float32 {
-	low := 0.0
-	high := 1.0
-	boundaryPoint := adversarial
-
-	for i := 0; i < 10; i++ {
-		mid := (low + high) / 2.0
-		
-		// mathutils/geometry.go 应包含 Interpolate
-		// candidate = original + (adversarial - original) * mid
-		// 即: Interpolate(original, adversarial, mid)
-		candidate := mathutils.Interpolate(original, adversarial, float32(mid))
-		candidate = mathutils.Clip(candidate, atk.config.ClipMin, atk.config.ClipMax)
-
-		if predict(candidate) != targetLabel {
-			high = mid
-			boundaryPoint = candidate
-		} else {
-			low = mid
+	for _, e := range atk.config.WarmStart.lookup(label, original, atk.config.WarmStartCandidates) {
+		step := mathutils.VectorScale(e.direction, float32(2*e.distance))
+		candidate := mathutils.Clip(mathutils.VectorAdd(original, step), atk.config.ClipMin, atk.config.ClipMax)
+		if predict(candidate) != label {
+			return candidate, true
 		}
 	}
-	return boundaryPoint
+	return nil, false
 }
 
 // approximateGradient 梯度估计
@@ -161,15 +244,15 @@ func (atk *HSJA) approximateGradient(sample []float32, label int, delta float32,
 	for j := 0; j < numEvals; j++ {
 		// 1. 生成高斯噪声 (noise.go)
 		noise := mathutils.GenGaussian(inputSize, 0, 1)
-		
+
 		// 2. 归一化 (geometry.go)
 		noise = mathutils.Normalize(noise)
-		
+
 		// 3. 构造扰动: sample + delta * noise
 		perturbation := mathutils.VectorScale(noise, delta)
 		posPoint := mathutils.VectorAdd(sample, perturbation)
 		posPoint = mathutils.Clip(posPoint, atk.config.ClipMin, atk.config.ClipMax)
-		
+
 		// 4. 查询并记录方向
 		pred := predict(posPoint)
 		if pred != label {
@@ -184,14 +267,16 @@ func (atk *HSJA) approximateGradient(sample []float32, label int, delta float32,
 	if len(validDirections) == 0 {
 		return mathutils.NewVector(inputSize, 0) // basic.go
 	}
-	
+
 	// 修正：假设 MeanVector 在 stats.go 中
 	grad := mathutils.MeanVector(validDirections)
 	return mathutils.Normalize(grad)
 }
 
 func (atk *HSJA) computeDelta(dist float32, iter int) float32 {
-	if iter == 0 { return 0.1 }
+	if iter == 0 {
+		return 0.1
+	}
 	return dist * 0.1 / float32(math.Sqrt(float64(iter)))
 }
 
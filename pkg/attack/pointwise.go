@@ -0,0 +1,121 @@
+package attack
+
+import (
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// PointwiseConfig 配置稀疏 (L0) 攻击参数
+type PointwiseConfig struct {
+	MaxQueries    int     // 最大查询次数限制 (不大于 0 时取默认 10000)，包含最后确认标签的 1 次查询
+	MaxIterations int     // 完整扫描轮数上限 (默认 10)，一轮中没有像素能被恢复时提前结束
+	InitEvals     int     // 初始化时的采样次数 (默认 100)
+	ClipMin       float32 // 0.0
+	ClipMax       float32 // 1.0
+}
+
+// Pointwise 稀疏决策攻击器 (Schott et al., 2019)
+// 从一个已被误分类的起点出发，逐个把像素恢复成原图的值，
+// 只要标签仍然被翻转就保留这次恢复。最终剩下的改动像素数 (空间位置) 即为 L0 距离。
+// 对 L2 噪声鲁棒、但对少数像素敏感的模型，这个计数本身就是成员信号。
+type Pointwise struct {
+	config PointwiseConfig
+}
+
+// NewPointwise 创建稀疏攻击器
+func NewPointwise(cfg PointwiseConfig) *Pointwise {
+	if cfg.MaxQueries <= 0 {
+		cfg.MaxQueries = 10000
+	}
+	if cfg.MaxIterations == 0 {
+		cfg.MaxIterations = 10
+	}
+	if cfg.InitEvals == 0 {
+		cfg.InitEvals = 100
+	}
+	return &Pointwise{config: cfg}
+}
+
+// Attack 实现 core.Attacker 接口
+// 返回的 Distance 为改动的像素个数 (见 changedPixels)，而不是 L2 距离。
+func (atk *Pointwise) Attack(sample core.Sample, model core.Model) core.AttackResult {
+	queries := 0
+
+	predictFunc := func(img []float32) int {
+		queries++
+		l, _ := model.Predict(img)
+		return l
+	}
+	// 预算是否耗尽：为最后确认标签的 1 次查询留出余量
+	exhausted := func() bool {
+		return queries+1 >= atk.config.MaxQueries
+	}
+
+	original := sample.Data
+	targetLabel := sample.Label
+
+	// 1. 初始化：寻找初始对抗样本
+	xAdv := uniformInit(original, targetLabel, atk.config.InitEvals, atk.config.ClipMin, atk.config.ClipMax, predictFunc)
+	if xAdv == nil {
+		return core.AttackResult{
+			SampleID: sample.ID, OriginalLabel: targetLabel, FinalLabel: targetLabel,
+			IsSuccess: false, Queries: queries, Distance: 0.0, IsMember: false,
+		}
+	}
+	xAdv = mathutils.Clone(xAdv)
+
+	// 2. 逐像素恢复：能还原就还原，直到一整轮都没有进展
+	for iter := 0; iter < atk.config.MaxIterations && !exhausted(); iter++ {
+		improved := false
+
+		for _, idx := range mathutils.RandPerm(len(original)) {
+			if exhausted() {
+				break
+			}
+			if xAdv[idx] == original[idx] {
+				continue
+			}
+
+			// 试着把这个像素改回原值
+			saved := xAdv[idx]
+			xAdv[idx] = original[idx]
+			if predictFunc(xAdv) != targetLabel {
+				improved = true
+			} else {
+				xAdv[idx] = saved
+			}
+		}
+
+		if !improved {
+			break
+		}
+	}
+
+	finalLabel := predictFunc(xAdv)
+
+	return core.AttackResult{
+		SampleID:      sample.ID,
+		OriginalLabel: targetLabel,
+		FinalLabel:    finalLabel,
+		IsSuccess:     finalLabel != targetLabel,
+		Queries:       queries,
+		Distance:      float64(changedPixels(original, xAdv)),
+		IsMember:      false,
+	}
+}
+
+// changedPixels 统计至少有一个通道被改动的像素位置数 (CHW 布局)。
+// 一个 RGB 像素的三个通道都被改动也只算 1 个，与 mathutils.L0Distance 按通道值计数不同。
+func changedPixels(a, b core.Image) int {
+	plane := core.ImgHeight * core.ImgWidth
+	count := 0
+	for p := 0; p < plane; p++ {
+		for c := p; c < len(a); c += plane {
+			if a[c] != b[c] {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
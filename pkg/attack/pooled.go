@@ -0,0 +1,96 @@
+package attack
+
+import (
+	"math"
+	"sync"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// AttackPooled 在提前停止模式下批量攻击：所有样本共享 len(samples) * MaxQueries 的总预算。
+// 第一轮每个样本最多用 MaxQueries 次查询，距离低于 StopThreshold 的样本提前结束；
+// 之后把省下的查询平均分给仍未定论的样本继续优化 (不再受 MaxIterations 限制)，
+// 直到预算用尽、所有样本都已定论，或剩余预算不够再做一轮迭代 (梯度估计 + 二分)。
+// 追加阶段只做完整放得下的迭代，并为每个样本留出最后确认标签的 1 次查询，不会超出总预算；
+// 第一轮与 Attack 相同，预算检查在每轮迭代之前，单个样本最多超出一轮迭代的查询。
+// 结果顺序与 samples 一致，DecisionFinal 标出哪些判定已定、哪些受预算限制。
+// StopThreshold 为 0 时等价于逐个调用 Attack。
+func (atk *HSJA) AttackPooled(samples []core.Sample, model core.Model, workers int) []core.AttackResult {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	runs := make([]*hsjaRun, len(samples))
+	parallel(len(samples), workers, func(i int) {
+		runs[i] = atk.start(samples[i], model)
+		if runs[i].xAdv != nil {
+			atk.refine(runs[i], atk.config.MaxQueries, atk.config.MaxIterations)
+		}
+	})
+
+	if atk.config.StopThreshold > 0 {
+		iterCost := atk.config.NumEvals + binarySearchSteps
+		remaining := func() int {
+			pool := len(samples) * atk.config.MaxQueries
+			for _, r := range runs {
+				pool -= r.queries
+				if r.xAdv != nil {
+					pool-- // finish 确认最终标签的 1 次查询
+				}
+			}
+			return pool
+		}
+		pool := remaining()
+
+		for {
+			var undecided []*hsjaRun
+			for _, r := range runs {
+				if r.xAdv != nil && !atk.settled(r) {
+					undecided = append(undecided, r)
+				}
+			}
+			extra := 0
+			if len(undecided) > 0 {
+				extra = pool / len(undecided)
+			}
+			// 连一轮完整的迭代都放不下就停止
+			if extra < iterCost {
+				break
+			}
+
+			// refine 在 queries < budget 时才开始新一轮，预算取 extra - iterCost + 1 保证本轮不超过 extra
+			parallel(len(undecided), workers, func(i int) {
+				r := undecided[i]
+				atk.refine(r, r.queries+extra-iterCost+1, math.MaxInt)
+			})
+			pool = remaining()
+		}
+	}
+
+	results := make([]core.AttackResult, len(runs))
+	for i, r := range runs {
+		results[i] = atk.finish(r)
+	}
+	return results
+}
+
+// parallel 用 workers 个 goroutine 执行 fn(0..n-1)
+func parallel(n, workers int, fn func(i int)) {
+	jobs := make(chan int, n)
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,223 @@
+package attack
+
+import (
+	"math"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/imaging"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// TransformKind 语义变换的种类
+type TransformKind int
+
+const (
+	TransformRotate TransformKind = iota
+	TransformTranslateX
+	TransformTranslateY
+	TransformScale
+	TransformBrightness
+	TransformContrast
+)
+
+// String 返回变换名称 (用于日志和报表)
+func (k TransformKind) String() string {
+	switch k {
+	case TransformRotate:
+		return "rotate"
+	case TransformTranslateX:
+		return "translate_x"
+	case TransformTranslateY:
+		return "translate_y"
+	case TransformScale:
+		return "scale"
+	case TransformBrightness:
+		return "brightness"
+	case TransformContrast:
+		return "contrast"
+	}
+	return "unknown"
+}
+
+// TransformConfig 配置变换空间攻击参数
+// 每种变换的幅度都被归一化到 [0, 1]：1 对应下面的 Max* 上限。
+type TransformConfig struct {
+	MaxQueries     int             // 最大查询次数限制 (0 表示不限制)
+	Kinds          []TransformKind // 参与搜索的变换 (默认全部)
+	GridSteps      int             // 粗扫的格点数 (默认 8)
+	SearchSteps    int             // 找到翻转区间后的二分次数 (默认 10)
+	MaxRotation    float64         // 最大旋转角度 (度，默认 30)
+	MaxTranslation float64         // 最大平移像素 (默认 8)
+	MaxScale       float64         // 最大缩放偏移，factor ∈ [1-MaxScale, 1+MaxScale] (默认 0.3，factor 最小截断为 minScaleFactor)
+	MaxBrightness  float32         // 最大亮度偏移 (默认 0.5)
+	MaxContrast    float32         // 最大对比度偏移，factor ∈ [1-MaxContrast, 1+MaxContrast] (默认 0.5)
+	ClipMin        float32         // 0.0
+	ClipMax        float32         // 1.0
+}
+
+// TransformAttack 变换空间边界距离攻击器
+// 不在像素空间里加噪声，而是搜索旋转/平移/缩放/亮度/对比度，
+// 找到能翻转标签的最小归一化变换幅度，作为 Distance 返回。
+// 对像素范数类防御 (对抗训练、输出加噪) 不如 HSJA 的 L2 距离敏感。
+//
+// 搜索先按 1/GridSteps 的间隔粗扫再二分，比一个格距更窄的翻转区间可能被跳过，
+// 所以 Distance 是真实最小幅度的上界；需要更紧的上界时调大 GridSteps。
+type TransformAttack struct {
+	config TransformConfig
+}
+
+// minScaleFactor 缩放因子的下限：MaxScale >= 1 时 1-MaxScale 会变成 0 或负数，
+// imaging.Scale 不接受这样的因子
+const minScaleFactor = 0.05
+
+// NewTransformAttack 创建变换空间攻击器
+func NewTransformAttack(cfg TransformConfig) *TransformAttack {
+	if len(cfg.Kinds) == 0 {
+		cfg.Kinds = []TransformKind{
+			TransformRotate, TransformTranslateX, TransformTranslateY,
+			TransformScale, TransformBrightness, TransformContrast,
+		}
+	}
+	if cfg.GridSteps == 0 {
+		cfg.GridSteps = 8
+	}
+	if cfg.SearchSteps == 0 {
+		cfg.SearchSteps = 10
+	}
+	if cfg.MaxRotation == 0 {
+		cfg.MaxRotation = 30
+	}
+	if cfg.MaxTranslation == 0 {
+		cfg.MaxTranslation = 8
+	}
+	if cfg.MaxScale == 0 {
+		cfg.MaxScale = 0.3
+	}
+	if cfg.MaxBrightness == 0 {
+		cfg.MaxBrightness = 0.5
+	}
+	if cfg.MaxContrast == 0 {
+		cfg.MaxContrast = 0.5
+	}
+	return &TransformAttack{config: cfg}
+}
+
+// Attack 实现 core.Attacker 接口
+// Distance 为所有变换中最小的归一化幅度 (0~1)；找不到任何翻转时攻击失败。
+// 几何变换只对 3x32x32 的图片有意义，其他尺寸的输入不发起查询，直接返回失败结果。
+func (atk *TransformAttack) Attack(sample core.Sample, model core.Model) core.AttackResult {
+	if len(sample.Data) != core.FlattenedSize {
+		return core.AttackResult{
+			SampleID: sample.ID, OriginalLabel: sample.Label, FinalLabel: sample.Label,
+			IsSuccess: false, Queries: 0, Distance: 0.0, IsMember: false,
+		}
+	}
+
+	queries := 0
+	exhausted := func() bool {
+		return atk.config.MaxQueries > 0 && queries >= atk.config.MaxQueries
+	}
+	predictFunc := func(img []float32) int {
+		queries++
+		l, _ := model.Predict(img)
+		return l
+	}
+
+	original := sample.Data
+	targetLabel := sample.Label
+
+	bestMag := 2.0 // 大于任何合法幅度
+	finalLabel := targetLabel
+
+	// 原图已经被误分类：距离为 0
+	if l := predictFunc(original); l != targetLabel {
+		bestMag, finalLabel = 0, l
+	}
+
+	for _, kind := range atk.config.Kinds {
+		for _, sign := range []float64{1, -1} {
+			if bestMag == 0 || exhausted() {
+				break
+			}
+			mag, label, ok := atk.searchDirection(original, targetLabel, kind, sign, bestMag, predictFunc, exhausted)
+			if ok && mag < bestMag {
+				bestMag, finalLabel = mag, label
+			}
+		}
+	}
+
+	if bestMag > 1 {
+		return core.AttackResult{
+			SampleID: sample.ID, OriginalLabel: targetLabel, FinalLabel: targetLabel,
+			IsSuccess: false, Queries: queries, Distance: 0.0, IsMember: false,
+		}
+	}
+
+	return core.AttackResult{
+		SampleID:      sample.ID,
+		OriginalLabel: targetLabel,
+		FinalLabel:    finalLabel,
+		IsSuccess:     true,
+		Queries:       queries,
+		Distance:      bestMag,
+		IsMember:      false,
+	}
+}
+
+// searchDirection 沿一种变换的一个方向搜索最小翻转幅度。
+// 先在 (0, limit] 内粗扫格点，找到第一个翻转点后在相邻格点之间二分。
+// limit 是当前已知的最优幅度，超过它的格点没有意义：最后一段只探测到 limit 本身，
+// 这样落在 limit 之前、但在下一个格点之后才会被扫到的翻转也能找到。
+func (atk *TransformAttack) searchDirection(original []float32, label int, kind TransformKind, sign, limit float64,
+	predict func([]float32) int, exhausted func() bool) (float64, int, bool) {
+
+	steps := atk.config.GridSteps
+	low := 0.0
+	for i := 1; i <= steps && low < limit; i++ {
+		if exhausted() {
+			return 0, label, false
+		}
+		high := math.Min(float64(i)/float64(steps), limit)
+
+		l := predict(atk.apply(original, kind, sign*high))
+		if l == label {
+			low = high
+			continue
+		}
+
+		// 在 [low, high] 之间二分，high 始终保持为对抗点
+		bestLabel := l
+		for j := 0; j < atk.config.SearchSteps && !exhausted(); j++ {
+			mid := (low + high) / 2.0
+			if l := predict(atk.apply(original, kind, sign*mid)); l != label {
+				high, bestLabel = mid, l
+			} else {
+				low = mid
+			}
+		}
+		return high, bestLabel, true
+	}
+	return 0, label, false
+}
+
+// apply 按归一化幅度 t (-1~1) 对图片做一次变换并裁剪到合法像素范围
+func (atk *TransformAttack) apply(img []float32, kind TransformKind, t float64) []float32 {
+	var out core.Image
+	switch kind {
+	case TransformRotate:
+		out = imaging.Rotate(img, t*atk.config.MaxRotation)
+	case TransformTranslateX:
+		out = imaging.Translate(img, t*atk.config.MaxTranslation, 0)
+	case TransformTranslateY:
+		out = imaging.Translate(img, 0, t*atk.config.MaxTranslation)
+	case TransformScale:
+		out = imaging.Scale(img, math.Max(1+t*atk.config.MaxScale, minScaleFactor))
+	case TransformBrightness:
+		out = imaging.AdjustBrightness(img, float32(t)*atk.config.MaxBrightness)
+	case TransformContrast:
+		out = imaging.AdjustContrast(img, 1+float32(t)*atk.config.MaxContrast)
+	default:
+		out = mathutils.Clone(img)
+	}
+	return mathutils.Clip(out, atk.config.ClipMin, atk.config.ClipMax)
+}
@@ -0,0 +1,122 @@
+package attack
+
+import (
+	"sort"
+	"sync"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/imaging"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// WarmStartCache 热启动缓存：按原始标签 + 低分辨率嵌入保存成功的扰动方向
+// 同一次审计中，同类样本的决策边界几何相近，
+// 用以前成功的方向初始化新攻击，可以省掉 InitEvals 的均匀噪声撒点。
+// 可被 worker.Auditor 的多个 goroutine 同时读写。
+type WarmStartCache struct {
+	mu       sync.RWMutex
+	side     int // 嵌入边长，CIFAR 图片被缩成 3 x side x side
+	capacity int // 每个标签最多保存的方向数，超出后淘汰最旧的
+	entries  map[int][]warmEntry
+}
+
+// warmEntry 一条缓存记录
+type warmEntry struct {
+	embedding []float32 // 原图的低分辨率嵌入
+	direction []float32 // 单位扰动方向 (xAdv - original)
+	distance  float64   // 该方向上找到的边界距离
+}
+
+// NewWarmStartCache 创建热启动缓存
+// embedSide 默认 8，capacityPerLabel 默认 64
+func NewWarmStartCache(embedSide, capacityPerLabel int) *WarmStartCache {
+	if embedSide == 0 {
+		embedSide = 8
+	}
+	if capacityPerLabel == 0 {
+		capacityPerLabel = 64
+	}
+	return &WarmStartCache{
+		side:     embedSide,
+		capacity: capacityPerLabel,
+		entries:  make(map[int][]warmEntry),
+	}
+}
+
+// Store 记录一次成功攻击：original 是原图，adversarial 是最终对抗样本
+func (c *WarmStartCache) Store(label int, original, adversarial []float32) {
+	diff := mathutils.VectorSub(adversarial, original)
+	dist := mathutils.L2Norm(diff)
+	if dist == 0 {
+		return
+	}
+
+	entry := warmEntry{
+		embedding: c.embed(original),
+		direction: mathutils.Normalize(diff),
+		distance:  dist,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list := append(c.entries[label], entry)
+	if len(list) > c.capacity {
+		list = list[len(list)-c.capacity:]
+	}
+	c.entries[label] = list
+}
+
+// lookup 返回与 original 嵌入最接近的至多 k 条记录 (由近到远)
+func (c *WarmStartCache) lookup(label int, original []float32, k int) []warmEntry {
+	emb := c.embed(original)
+
+	c.mu.RLock()
+	list := make([]warmEntry, len(c.entries[label]))
+	copy(list, c.entries[label])
+	c.mu.RUnlock()
+
+	dists := make([]float64, len(list))
+	for i, e := range list {
+		dists[i] = mathutils.L2Distance(emb, e.embedding)
+	}
+	sort.Sort(byDistance{list, dists})
+
+	if k < len(list) {
+		list = list[:k]
+	}
+	return list
+}
+
+// Len 返回缓存中的记录总数
+func (c *WarmStartCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	n := 0
+	for _, list := range c.entries {
+		n += len(list)
+	}
+	return n
+}
+
+// embed 把图片缩成低分辨率向量；非 CIFAR 尺寸的输入直接复制
+func (c *WarmStartCache) embed(img []float32) []float32 {
+	if len(img) != core.FlattenedSize {
+		return mathutils.Clone(img)
+	}
+	return imaging.Resize(img, core.ImgChannels, core.ImgHeight, core.ImgWidth, c.side, c.side)
+}
+
+// byDistance 按嵌入距离对记录排序
+type byDistance struct {
+	entries []warmEntry
+	dists   []float64
+}
+
+func (b byDistance) Len() int           { return len(b.entries) }
+func (b byDistance) Less(i, j int) bool { return b.dists[i] < b.dists[j] }
+func (b byDistance) Swap(i, j int) {
+	b.entries[i], b.entries[j] = b.entries[j], b.entries[i]
+	b.dists[i], b.dists[j] = b.dists[j], b.dists[i]
+}
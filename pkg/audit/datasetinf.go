@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// TestKind 比较距离分布时使用的检验
+type TestKind int
+
+const (
+	// WelchT 单侧 Welch t 检验 (比较均值)
+	WelchT TestKind = iota
+	// MannWhitney 单侧 Mann-Whitney U 检验 (只看秩，对离群值和攻击失败更稳健)
+	MannWhitney
+)
+
+// DatasetInferenceConfig 数据集推断参数
+type DatasetInferenceConfig struct {
+	Test       TestKind
+	Alpha      float64 // 显著性水平 (默认 0.01)
+	Sequential bool    // 是否分批攻击并在显著后提前停止
+	BatchSize  int     // 顺序模式下每批从两个集合各取多少个样本 (默认 10)
+	Workers    int     // 并发攻击的 goroutine 数 (默认 1)
+}
+
+// DatasetInferenceResult 数据集推断结论
+type DatasetInferenceResult struct {
+	Test         eval.TestResult
+	Significant  bool    // p 值 < 有效显著性水平 => 认为可疑集被用于训练
+	Alpha        float64 // 实际使用的显著性水平 (顺序模式下经过 Bonferroni 校正)
+	SuspectUsed  int     // 实际攻击的可疑样本数
+	UnseenUsed   int     // 实际攻击的未见样本数
+	SuspectMean  float64
+	UnseenMean   float64
+	Queries      int // 两个集合合计消耗的查询次数
+	Looks        int // 做了几次检验
+	StoppedEarly bool
+}
+
+// DatasetInference 回答“这个数据集是否被用来训练过模型”:
+// 用同一个攻击器分别攻击可疑集与同分布的已知未见集，
+// 单侧检验可疑集的边界距离是否显著更大 (训练样本离边界更远)。
+//
+// 顺序模式下每次各追加 BatchSize 个样本后做一次检验，显著即停止以节省查询。
+// 多次查看会抬高第一类错误，因此每次检验使用 Alpha / 计划检验次数 (Bonferroni)，
+// 整体假阳性率仍不超过 Alpha。
+func DatasetInference(attacker core.Attacker, model core.Model, suspect, unseen []core.Sample, cfg DatasetInferenceConfig) (*DatasetInferenceResult, error) {
+	if cfg.Alpha == 0 {
+		cfg.Alpha = 0.01
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 10
+	}
+	if len(suspect) < 2 || len(unseen) < 2 {
+		return nil, fmt.Errorf("audit: 可疑集与未见集各至少需要 2 个样本 (%d / %d)", len(suspect), len(unseen))
+	}
+
+	batch := max(len(suspect), len(unseen))
+	if cfg.Sequential {
+		batch = cfg.BatchSize
+	}
+	plannedLooks := (max(len(suspect), len(unseen)) + batch - 1) / batch
+	res := &DatasetInferenceResult{Alpha: cfg.Alpha / float64(plannedLooks)}
+
+	var suspectResults, unseenResults []core.AttackResult
+	for look := 0; look < plannedLooks; look++ {
+		lo, hi := look*batch, (look+1)*batch
+		suspectResults = append(suspectResults, AttackAll(attacker, model, window(suspect, lo, hi), cfg.Workers)...)
+		unseenResults = append(unseenResults, AttackAll(attacker, model, window(unseen, lo, hi), cfg.Workers)...)
+		if len(suspectResults) < 2 || len(unseenResults) < 2 {
+			continue
+		}
+
+		test, err := compareDistances(distances(suspectResults), distances(unseenResults), cfg.Test, eval.Greater)
+		if err != nil {
+			return nil, err
+		}
+		res.Test = test
+		res.Looks++
+		if test.PValue < res.Alpha {
+			res.Significant = true
+			res.StoppedEarly = look < plannedLooks-1
+			break
+		}
+	}
+
+	fs := finite(distances(suspectResults), distances(unseenResults))
+	res.SuspectUsed, res.UnseenUsed = len(suspectResults), len(unseenResults)
+	res.SuspectMean, res.UnseenMean = mathutils.Mean(fs[0]), mathutils.Mean(fs[1])
+	res.Queries = totalQueries(suspectResults) + totalQueries(unseenResults)
+	return res, nil
+}
+
+// String 输出数据集推断结论
+func (r *DatasetInferenceResult) String() string {
+	var b strings.Builder
+	verdict := "❎ 未发现显著证据表明该数据集被用于训练"
+	if r.Significant {
+		verdict = "⚠️  该数据集很可能被用于训练"
+	}
+	fmt.Fprintf(&b, "%s\n", verdict)
+	fmt.Fprintf(&b, "p 值 %.4g (显著性水平 %.4g)，统计量 %.4f\n", r.Test.PValue, r.Alpha, r.Test.Statistic)
+	fmt.Fprintf(&b, "平均距离: 可疑集 %.4f (%d 个) / 未见集 %.4f (%d 个)\n", r.SuspectMean, r.SuspectUsed, r.UnseenMean, r.UnseenUsed)
+	fmt.Fprintf(&b, "查询次数 %d，检验 %d 次", r.Queries, r.Looks)
+	if r.StoppedEarly {
+		fmt.Fprintf(&b, " (已提前停止)")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// compareDistances 按检验种类比较两组距离
+func compareDistances(a, b []float64, kind TestKind, alt eval.Alternative) (eval.TestResult, error) {
+	if kind == MannWhitney {
+		return eval.MannWhitneyU(a, b, alt)
+	}
+	fs := finite(a, b)
+	return eval.WelchTTest(fs[0], fs[1], alt)
+}
+
+// window 返回 samples[lo:hi]，越界部分截断
+func window(samples []core.Sample, lo, hi int) []core.Sample {
+	return samples[min(lo, len(samples)):min(hi, len(samples))]
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"math"
+	"sync"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+)
+
+// ============================================================================
+// 集合级审计 (Set-level Audits)
+// 单样本 MIA 回答“这张图是否在训练集里”；这里回答的是集合层面的问题:
+// 整个数据集是否被用于训练、遗忘是否真的发生、金丝雀暴露了多少。
+// ============================================================================
+
+// AttackAll 用 workers 个 goroutine 并发攻击 samples，结果顺序与 samples 一致。
+// 并发方式与 LabelScan-Go 的 worker.Auditor 相同。
+func AttackAll(attacker core.Attacker, model core.Model, samples []core.Sample, workers int) []core.AttackResult {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	results := make([]core.AttackResult, len(samples))
+	jobs := make(chan int, len(samples))
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = attacker.Attack(samples[idx], model)
+			}
+		}()
+	}
+
+	for i := range samples {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// distances 取出成员分数 (攻击失败为 +Inf，见 eval.Score)
+func distances(results []core.AttackResult) []float64 {
+	d := make([]float64, len(results))
+	for i, r := range results {
+		d[i] = eval.Score(r)
+	}
+	return d
+}
+
+// finite 把 +Inf 替换成所有组中有限值的最大值，供需要均值/方差的检验使用
+func finite(groups ...[]float64) [][]float64 {
+	hi := 0.0
+	for _, g := range groups {
+		for _, v := range g {
+			if !math.IsInf(v, 0) && v > hi {
+				hi = v
+			}
+		}
+	}
+
+	out := make([][]float64, len(groups))
+	for i, g := range groups {
+		out[i] = make([]float64, len(g))
+		for j, v := range g {
+			if math.IsInf(v, 1) {
+				v = hi
+			}
+			out[i][j] = v
+		}
+	}
+	return out
+}
+
+func totalQueries(results []core.AttackResult) int {
+	n := 0
+	for _, r := range results {
+		n += r.Queries
+	}
+	return n
+}
@@ -0,0 +1,215 @@
+package audit
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// UnlearningSets 遗忘验证使用的样本集合
+type UnlearningSets struct {
+	Forget []core.Sample // 厂商声称已遗忘的样本
+	Retain []core.Sample // 仍应保留在训练集中的样本
+	Unseen []core.Sample // 可选：从未参与训练的同分布样本，用来衡量遗忘集离非成员分布还有多远
+}
+
+// UnlearningConfig 遗忘验证参数
+type UnlearningConfig struct {
+	Test            TestKind             // 遗忘前后是同一批样本，WelchT 对应配对 t 检验，MannWhitney 对应 Wilcoxon 符号秩检验
+	Alpha           float64              // 显著性水平 (默认 0.05)
+	RetainTolerance float64              // 保留集平均距离的相对变化不超过该值才视为“未变” (默认 0.1)
+	Bootstrap       eval.BootstrapConfig // 判定置信度的重采样参数 (Iterations 为 0 时默认 1000)
+	Workers         int
+}
+
+// SetShift 某个集合在遗忘前后的距离变化
+type SetShift struct {
+	BeforeMean     float64
+	AfterMean      float64
+	RelativeChange float64 // (after - before) / before
+	Test           eval.TestResult
+}
+
+// UnlearningResult 遗忘验证结论
+type UnlearningResult struct {
+	Forget SetShift // 单侧配对检验：遗忘后距离是否显著变小
+	Retain SetShift // 双侧配对检验：遗忘后距离是否显著改变
+
+	// 仅当提供了 Unseen 时有效
+	HasUnseen  bool
+	UnseenMean float64         // 未见集在遗忘后模型上的平均距离
+	GapClosed  float64         // 遗忘集与未见集的均值差被消除的比例 (1 表示完全回到非成员水平)
+	VsUnseen   eval.TestResult // 双侧检验：遗忘后的遗忘集与未见集是否仍可区分
+
+	ForgetMoved   bool
+	RetainKept    bool
+	RetainShifted bool // 保留集的变化在统计上显著 (只作提示：样本多时很小的变化也会显著，判定只看 RetainTolerance)
+	Passed        bool
+	Confidence    float64 // 重采样中得到相同判定的比例
+	Alpha         float64
+	Queries       int
+}
+
+// VerifyUnlearning 核验“指定样本已被遗忘”的声明。
+// 成员离决策边界更远，真正遗忘后遗忘集的边界距离应当向非成员水平下降，
+// 而保留集的距离应基本不变 (否则说明模型被整体破坏，而不是定向遗忘)。
+//
+// 遗忘前后攻击的是同一批样本，检验都在逐样本差值上配对进行。
+// 判定通过需要同时满足：
+//   - 遗忘集在遗忘后模型上的距离显著小于遗忘前 (单侧 p < Alpha)
+//   - 保留集平均距离的相对变化不超过 RetainTolerance
+//
+// 保留集的双侧检验只用来标记 RetainShifted：检验不显著并不能说明没有变化 (样本少时什么都不显著)。
+//
+// 置信度通过对已得到的距离做配对重采样估计，不会产生额外查询。
+func VerifyUnlearning(attacker core.Attacker, before, after core.Model, sets UnlearningSets, cfg UnlearningConfig) (*UnlearningResult, error) {
+	if cfg.Alpha == 0 {
+		cfg.Alpha = 0.05
+	}
+	if cfg.RetainTolerance == 0 {
+		cfg.RetainTolerance = 0.1
+	}
+	if cfg.Bootstrap.Iterations == 0 {
+		cfg.Bootstrap.Iterations = 1000
+	}
+	if len(sets.Forget) < 2 || len(sets.Retain) < 2 {
+		return nil, fmt.Errorf("audit: 遗忘集与保留集各至少需要 2 个样本 (%d / %d)", len(sets.Forget), len(sets.Retain))
+	}
+
+	var queries int
+	attack := func(model core.Model, samples []core.Sample) []float64 {
+		results := AttackAll(attacker, model, samples, cfg.Workers)
+		queries += totalQueries(results)
+		return distances(results)
+	}
+
+	forgetBefore, forgetAfter := attack(before, sets.Forget), attack(after, sets.Forget)
+	retainBefore, retainAfter := attack(before, sets.Retain), attack(after, sets.Retain)
+
+	res := &UnlearningResult{Alpha: cfg.Alpha}
+	var err error
+	if res.Forget, err = shift(forgetBefore, forgetAfter, cfg.Test, eval.Less); err != nil {
+		return nil, err
+	}
+	if res.Retain, err = shift(retainBefore, retainAfter, cfg.Test, eval.TwoSided); err != nil {
+		return nil, err
+	}
+	res.ForgetMoved, res.RetainKept = verdict(res.Forget, res.Retain, cfg)
+	res.RetainShifted = res.Retain.Test.PValue < cfg.Alpha
+	res.Passed = res.ForgetMoved && res.RetainKept
+
+	if len(sets.Unseen) >= 2 {
+		unseenAfter := attack(after, sets.Unseen)
+		fs := finite(forgetBefore, forgetAfter, unseenAfter)
+		res.HasUnseen = true
+		res.UnseenMean = mathutils.Mean(fs[2])
+		if gap := mathutils.Mean(fs[0]) - res.UnseenMean; gap != 0 {
+			res.GapClosed = (mathutils.Mean(fs[0]) - mathutils.Mean(fs[1])) / gap
+		}
+		if res.VsUnseen, err = compareDistances(forgetAfter, unseenAfter, cfg.Test, eval.TwoSided); err != nil {
+			return nil, err
+		}
+	}
+
+	res.Confidence = verdictConfidence(forgetBefore, forgetAfter, retainBefore, retainAfter, res.Passed, cfg)
+	res.Queries = queries
+	return res, nil
+}
+
+// String 输出遗忘验证结论
+func (r *UnlearningResult) String() string {
+	var b strings.Builder
+	verdict := "❌ 未通过"
+	if r.Passed {
+		verdict = "✅ 通过"
+	}
+	fmt.Fprintf(&b, "遗忘验证: %s (置信度 %.1f%%，显著性水平 %g)\n", verdict, r.Confidence*100, r.Alpha)
+	fmt.Fprintf(&b, "遗忘集: 平均距离 %.4f -> %.4f (%+.1f%%)，单侧 p = %.4g\n",
+		r.Forget.BeforeMean, r.Forget.AfterMean, r.Forget.RelativeChange*100, r.Forget.Test.PValue)
+	fmt.Fprintf(&b, "保留集: 平均距离 %.4f -> %.4f (%+.1f%%)，双侧 p = %.4g\n",
+		r.Retain.BeforeMean, r.Retain.AfterMean, r.Retain.RelativeChange*100, r.Retain.Test.PValue)
+	if r.RetainShifted && r.RetainKept {
+		b.WriteString("提示: 保留集的变化在统计上显著，但幅度在容差以内\n")
+	}
+	if r.HasUnseen {
+		fmt.Fprintf(&b, "未见集: 平均距离 %.4f，遗忘集与其差距已消除 %.1f%%，双侧 p = %.4g\n",
+			r.UnseenMean, r.GapClosed*100, r.VsUnseen.PValue)
+	}
+	if !r.ForgetMoved {
+		b.WriteString("原因: 遗忘集的距离没有显著下降，模型可能仍记得这些样本\n")
+	}
+	if !r.RetainKept {
+		b.WriteString("原因: 保留集的平均距离变化超过容差，遗忘不是定向的\n")
+	}
+	fmt.Fprintf(&b, "查询次数 %d\n", r.Queries)
+	return b.String()
+}
+
+// shift 计算一个集合遗忘前后的均值变化，并在逐样本差值 (遗忘后 - 遗忘前) 上做配对检验。
+// 攻击失败的 +Inf 先换成有限的最大值，前后都失败的样本差值为 0。
+func shift(before, after []float64, kind TestKind, alt eval.Alternative) (SetShift, error) {
+	fs := finite(before, after)
+	var test eval.TestResult
+	var err error
+	if kind == MannWhitney {
+		test, err = eval.WilcoxonSignedRank(fs[1], fs[0], alt)
+	} else {
+		test, err = eval.PairedTTest(fs[1], fs[0], alt)
+	}
+	if err != nil {
+		return SetShift{}, err
+	}
+	s := SetShift{BeforeMean: mathutils.Mean(fs[0]), AfterMean: mathutils.Mean(fs[1]), Test: test}
+	if s.BeforeMean != 0 {
+		s.RelativeChange = (s.AfterMean - s.BeforeMean) / s.BeforeMean
+	}
+	return s, nil
+}
+
+func verdict(forget, retain SetShift, cfg UnlearningConfig) (forgetMoved, retainKept bool) {
+	forgetMoved = forget.Test.PValue < cfg.Alpha
+	retainKept = math.Abs(retain.RelativeChange) <= cfg.RetainTolerance
+	return forgetMoved, retainKept
+}
+
+// verdictConfidence 对每个集合按样本配对重采样 (同一样本的前后距离一起抽)，
+// 重新判定，返回与原判定一致的比例
+func verdictConfidence(forgetBefore, forgetAfter, retainBefore, retainAfter []float64, passed bool, cfg UnlearningConfig) float64 {
+	rng := rand.New(rand.NewSource(cfg.Bootstrap.Seed))
+	resample := func(before, after []float64) ([]float64, []float64) {
+		b, a := make([]float64, len(before)), make([]float64, len(after))
+		for i := range before {
+			k := rng.Intn(len(before))
+			b[i], a[i] = before[k], after[k]
+		}
+		return b, a
+	}
+
+	agree, valid := 0, 0
+	for i := 0; i < cfg.Bootstrap.Iterations; i++ {
+		fb, fa := resample(forgetBefore, forgetAfter)
+		rb, ra := resample(retainBefore, retainAfter)
+		forget, err := shift(fb, fa, cfg.Test, eval.Less)
+		if err != nil {
+			continue
+		}
+		retain, err := shift(rb, ra, cfg.Test, eval.TwoSided)
+		if err != nil {
+			continue
+		}
+		moved, kept := verdict(forget, retain, cfg)
+		valid++
+		if (moved && kept) == passed {
+			agree++
+		}
+	}
+	if valid == 0 {
+		return math.NaN()
+	}
+	return float64(agree) / float64(valid)
+}
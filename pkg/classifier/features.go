@@ -0,0 +1,122 @@
+package classifier
+
+import (
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/imaging"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// ============================================================================
+// 多特征成员分类器 (Learned Membership Classifier)
+// 每种攻击只给出一个标量；这里把多个信号拼成特征向量，
+// 在带标签的影子数据上训练一个纯 Go 的小分类器 (逻辑回归 / 梯度提升树桩)，
+// 再应用到审计数据上。
+// ============================================================================
+
+// FeatureNames 特征向量各维的含义 (顺序与 Features.Vector 一致)
+var FeatureNames = []string{"distance", "queries", "success", "noise_robustness", "aug_robustness"}
+
+// Features 与一条 core.AttackResult 一一对应的特征
+type Features struct {
+	SampleID        int
+	Distance        float64 // 边界距离 (攻击失败时为 0，由 Success 区分)
+	Queries         float64 // 攻击消耗的查询次数
+	Success         float64 // 攻击是否成功 (0/1)
+	NoiseRobustness float64 // 加高斯噪声后仍被正确分类的比例
+	AugRobustness   float64 // 翻转 / 平移后仍被正确分类的比例 (Choquette-Choo et al., 2021)
+}
+
+// Vector 转成定长特征向量
+func (f Features) Vector() []float64 {
+	return []float64{f.Distance, f.Queries, f.Success, f.NoiseRobustness, f.AugRobustness}
+}
+
+// Extractor 从攻击结果和额外的模型查询中提取特征
+type Extractor struct {
+	NoiseSamples int     // 噪声鲁棒性的采样次数 (默认 20)
+	NoiseStd     float64 // 噪声标准差 (默认 0.05)
+	ClipMin      float32 // 0.0
+	ClipMax      float32 // 1.0
+}
+
+// NewExtractor 创建特征提取器
+func NewExtractor(e Extractor) *Extractor {
+	if e.NoiseSamples == 0 {
+		e.NoiseSamples = 20
+	}
+	if e.NoiseStd == 0 {
+		e.NoiseStd = 0.05
+	}
+	return &e
+}
+
+// Extract 提取一个样本的全部特征。
+// 鲁棒性特征需要额外查询模型 (NoiseSamples + 5 次)；
+// 非 CIFAR 尺寸的输入无法做几何增强，AugRobustness 记为 0。
+func (e *Extractor) Extract(sample core.Sample, result core.AttackResult, model core.Model) Features {
+	f := Features{
+		SampleID: sample.ID,
+		Distance: result.Distance,
+		Queries:  float64(result.Queries),
+	}
+	if result.IsSuccess {
+		f.Success = 1
+	}
+
+	correct := func(img core.Image) bool {
+		l, err := model.Predict(img)
+		return err == nil && l == sample.Label
+	}
+
+	// 1. 噪声鲁棒性
+	kept := 0
+	for i := 0; i < e.NoiseSamples; i++ {
+		noise := mathutils.GenGaussian(len(sample.Data), 0, e.NoiseStd)
+		noisy := mathutils.Clip(mathutils.VectorAdd(sample.Data, noise), e.ClipMin, e.ClipMax)
+		if correct(noisy) {
+			kept++
+		}
+	}
+	f.NoiseRobustness = float64(kept) / float64(e.NoiseSamples)
+
+	// 2. 增强鲁棒性：训练时常用的翻转与小平移
+	if len(sample.Data) == core.FlattenedSize {
+		augs := []core.Image{
+			imaging.FlipHorizontal(sample.Data),
+			imaging.Translate(sample.Data, 2, 0),
+			imaging.Translate(sample.Data, -2, 0),
+			imaging.Translate(sample.Data, 0, 2),
+			imaging.Translate(sample.Data, 0, -2),
+		}
+		kept = 0
+		for _, img := range augs {
+			if correct(img) {
+				kept++
+			}
+		}
+		f.AugRobustness = float64(kept) / float64(len(augs))
+	}
+	return f
+}
+
+// FromResults 只用成绩单里已有的信息构造特征。
+// 没有模型就无法测鲁棒性，两项鲁棒性特征记为 0 (训练时按常数列处理，不影响其他特征)。
+func FromResults(results []core.AttackResult) []Features {
+	features := make([]Features, len(results))
+	for i, r := range results {
+		features[i] = Features{SampleID: r.SampleID, Distance: r.Distance, Queries: float64(r.Queries)}
+		if r.IsSuccess {
+			features[i].Success = 1
+		}
+	}
+	return features
+}
+
+// FeatureMatrix 把特征列表转成训练用的矩阵
+func FeatureMatrix(features []Features) [][]float64 {
+	x := make([][]float64, len(features))
+	for i, f := range features {
+		x[i] = f.Vector()
+	}
+	return x
+}
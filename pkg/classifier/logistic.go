@@ -0,0 +1,148 @@
+package classifier
+
+import (
+	"fmt"
+	"math"
+
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// Classifier 输出成员概率的分类器
+type Classifier interface {
+	// PredictProba 返回 P(member | x)
+	PredictProba(x []float64) float64
+}
+
+// LogisticConfig 逻辑回归训练参数
+type LogisticConfig struct {
+	Iterations   int     // 全批量梯度下降轮数 (默认 500)
+	LearningRate float64 // 学习率 (默认 0.5)
+	L2           float64 // L2 正则系数 (默认 1e-3)
+}
+
+// Logistic 带特征标准化的逻辑回归
+type Logistic struct {
+	Mean    []float64 `json:"mean"`
+	Std     []float64 `json:"std"`
+	Weights []float64 `json:"weights"`
+	Bias    float64   `json:"bias"`
+}
+
+// TrainLogistic 在 (x, y) 上训练逻辑回归
+func TrainLogistic(x [][]float64, y []bool, cfg LogisticConfig) (*Logistic, error) {
+	if err := checkTrainingSet(x, y); err != nil {
+		return nil, err
+	}
+	if cfg.Iterations == 0 {
+		cfg.Iterations = 500
+	}
+	if cfg.LearningRate == 0 {
+		cfg.LearningRate = 0.5
+	}
+	if cfg.L2 == 0 {
+		cfg.L2 = 1e-3
+	}
+
+	dim := len(x[0])
+	m := &Logistic{Weights: make([]float64, dim)}
+	m.Mean, m.Std = columnStats(x)
+
+	z := make([][]float64, len(x))
+	for i, row := range x {
+		z[i] = m.standardize(row)
+	}
+
+	n := float64(len(x))
+	grad := make([]float64, dim)
+	for iter := 0; iter < cfg.Iterations; iter++ {
+		for j := range grad {
+			grad[j] = cfg.L2 * m.Weights[j]
+		}
+		gb := 0.0
+		for i, row := range z {
+			err := mathutils.Sigmoid(dot(m.Weights, row)+m.Bias) - label(y[i])
+			for j, v := range row {
+				grad[j] += err * v / n
+			}
+			gb += err / n
+		}
+		for j := range m.Weights {
+			m.Weights[j] -= cfg.LearningRate * grad[j]
+		}
+		m.Bias -= cfg.LearningRate * gb
+	}
+	return m, nil
+}
+
+// PredictProba 实现 Classifier 接口
+func (m *Logistic) PredictProba(x []float64) float64 {
+	return mathutils.Sigmoid(dot(m.Weights, m.standardize(x)) + m.Bias)
+}
+
+func (m *Logistic) standardize(x []float64) []float64 {
+	z := make([]float64, len(x))
+	for j, v := range x {
+		z[j] = (v - m.Mean[j]) / m.Std[j]
+	}
+	return z
+}
+
+// checkTrainingSet 检查训练数据非空、特征与标签一一对应且每行维数相同
+func checkTrainingSet(x [][]float64, y []bool) error {
+	if len(x) == 0 {
+		return fmt.Errorf("classifier: 训练集为空")
+	}
+	if len(x) != len(y) {
+		return fmt.Errorf("classifier: %d 个特征向量与 %d 个标签不对应", len(x), len(y))
+	}
+	for i, row := range x {
+		if len(row) != len(x[0]) {
+			return fmt.Errorf("classifier: 第 %d 个特征向量有 %d 维，第一个有 %d 维", i, len(row), len(x[0]))
+		}
+	}
+	if len(x[0]) == 0 {
+		return fmt.Errorf("classifier: 特征向量为空")
+	}
+	return nil
+}
+
+// columnStats 逐列均值与标准差 (常数列的标准差记为 1)
+func columnStats(x [][]float64) ([]float64, []float64) {
+	dim := len(x[0])
+	mean := make([]float64, dim)
+	std := make([]float64, dim)
+	n := float64(len(x))
+
+	for _, row := range x {
+		for j, v := range row {
+			mean[j] += v / n
+		}
+	}
+	for _, row := range x {
+		for j, v := range row {
+			std[j] += (v - mean[j]) * (v - mean[j]) / n
+		}
+	}
+	for j := range std {
+		std[j] = math.Sqrt(std[j])
+		if std[j] == 0 {
+			std[j] = 1
+		}
+	}
+	return mean, std
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func label(member bool) float64 {
+	if member {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// BoostConfig 梯度提升树桩的训练参数
+type BoostConfig struct {
+	Rounds       int     // 提升轮数 (默认 100)
+	LearningRate float64 // 收缩系数 (默认 0.1)
+	MaxSplits    int     // 每个特征最多尝试的切分点数，按分位数取 (默认 32)
+}
+
+// Stump 单层决策树：x[Feature] <= Threshold 时输出 Left，否则输出 Right
+type Stump struct {
+	Feature   int     `json:"feature"`
+	Threshold float64 `json:"threshold"`
+	Left      float64 `json:"left"`
+	Right     float64 `json:"right"`
+}
+
+// BoostedStumps 以对数损失训练的梯度提升树桩 (对数几率空间累加)
+type BoostedStumps struct {
+	Base         float64 `json:"base"` // 初始对数几率 log(p / (1-p))
+	LearningRate float64 `json:"learning_rate"`
+	Stumps       []Stump `json:"stumps"`
+}
+
+// TrainStumps 在 (x, y) 上训练梯度提升树桩
+func TrainStumps(x [][]float64, y []bool, cfg BoostConfig) (*BoostedStumps, error) {
+	if err := checkTrainingSet(x, y); err != nil {
+		return nil, err
+	}
+	if cfg.Rounds == 0 {
+		cfg.Rounds = 100
+	}
+	if cfg.LearningRate == 0 {
+		cfg.LearningRate = 0.1
+	}
+	if cfg.MaxSplits == 0 {
+		cfg.MaxSplits = 32
+	}
+
+	n := len(x)
+	pos := 0.0
+	for _, m := range y {
+		pos += label(m)
+	}
+	p := math.Min(math.Max(pos/float64(n), 1e-6), 1-1e-6)
+
+	model := &BoostedStumps{Base: math.Log(p / (1 - p)), LearningRate: cfg.LearningRate}
+	splits := candidateSplits(x, cfg.MaxSplits)
+
+	f := make([]float64, n) // 当前对数几率
+	for i := range f {
+		f[i] = model.Base
+	}
+
+	grad := make([]float64, n)
+	hess := make([]float64, n)
+	for round := 0; round < cfg.Rounds; round++ {
+		for i := range f {
+			prob := mathutils.Sigmoid(f[i])
+			grad[i] = label(y[i]) - prob // 负梯度
+			hess[i] = prob * (1 - prob)
+		}
+
+		stump, ok := bestStump(x, grad, hess, splits)
+		if !ok {
+			break
+		}
+		model.Stumps = append(model.Stumps, stump)
+		for i, row := range x {
+			f[i] += cfg.LearningRate * stump.value(row)
+		}
+	}
+	return model, nil
+}
+
+// PredictProba 实现 Classifier 接口
+func (m *BoostedStumps) PredictProba(x []float64) float64 {
+	f := m.Base
+	for _, s := range m.Stumps {
+		f += m.LearningRate * s.value(x)
+	}
+	return mathutils.Sigmoid(f)
+}
+
+func (s Stump) value(x []float64) float64 {
+	if x[s.Feature] <= s.Threshold {
+		return s.Left
+	}
+	return s.Right
+}
+
+// bestStump 选出使二阶近似损失下降最多的切分 (增益 = G_L²/H_L + G_R²/H_R)，
+// 叶子值为牛顿步 G / H。
+func bestStump(x [][]float64, grad, hess []float64, splits [][]float64) (Stump, bool) {
+	const lambda = 1.0 // 叶子值的 L2 正则，防止 H 很小时叶子值爆炸
+
+	var best Stump
+	bestGain := 0.0
+	found := false
+
+	for feat, thresholds := range splits {
+		for _, thr := range thresholds {
+			var gl, hl, gr, hr float64
+			for i, row := range x {
+				if row[feat] <= thr {
+					gl += grad[i]
+					hl += hess[i]
+				} else {
+					gr += grad[i]
+					hr += hess[i]
+				}
+			}
+			gain := gl*gl/(hl+lambda) + gr*gr/(hr+lambda)
+			if gain > bestGain {
+				bestGain = gain
+				best = Stump{Feature: feat, Threshold: thr, Left: gl / (hl + lambda), Right: gr / (hr + lambda)}
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+// candidateSplits 为每个特征取至多 maxSplits 个分位数作为候选切分点
+func candidateSplits(x [][]float64, maxSplits int) [][]float64 {
+	dim := len(x[0])
+	splits := make([][]float64, dim)
+
+	for feat := 0; feat < dim; feat++ {
+		values := make([]float64, len(x))
+		for i, row := range x {
+			values[i] = row[feat]
+		}
+		sort.Float64s(values)
+
+		seen := make(map[float64]bool)
+		for k := 1; k <= maxSplits; k++ {
+			v := values[(len(values)-1)*k/(maxSplits+1)]
+			// 只有小于最大值的切分点才能把样本分成两边
+			if v < values[len(values)-1] && !seen[v] {
+				seen[v] = true
+				splits[feat] = append(splits[feat], v)
+			}
+		}
+	}
+	return splits
+}
@@ -0,0 +1,186 @@
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+)
+
+// Trainer 训练一个分类器的函数 (便于在交叉验证中替换模型种类)
+type Trainer func(x [][]float64, y []bool) (Classifier, error)
+
+// LogisticTrainer 返回逻辑回归的 Trainer
+func LogisticTrainer(cfg LogisticConfig) Trainer {
+	return func(x [][]float64, y []bool) (Classifier, error) {
+		m, err := TrainLogistic(x, y, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+// StumpsTrainer 返回梯度提升树桩的 Trainer
+func StumpsTrainer(cfg BoostConfig) Trainer {
+	return func(x [][]float64, y []bool) (Classifier, error) {
+		m, err := TrainStumps(x, y, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+// CVResult k 折交叉验证结果
+type CVResult struct {
+	FoldAUCs []float64
+	MeanAUC  float64
+	StdAUC   float64
+}
+
+// CrossValidate 在影子数据上做 k 折交叉验证，返回每折验证集上的 AUC。
+// 折的划分由 seed 决定；某折验证集只含一类样本时该折 AUC 记为 NaN 并不计入均值。
+func CrossValidate(x [][]float64, y []bool, k int, seed int64, train Trainer) (CVResult, error) {
+	if len(x) != len(y) {
+		return CVResult{}, fmt.Errorf("classifier: %d 个特征向量与 %d 个标签不对应", len(x), len(y))
+	}
+	if k < 2 || k > len(x) {
+		return CVResult{}, fmt.Errorf("classifier: 折数 %d 不合法 (样本数 %d)", k, len(x))
+	}
+
+	perm := rand.New(rand.NewSource(seed)).Perm(len(x))
+
+	var res CVResult
+	var valid []float64
+	for fold := 0; fold < k; fold++ {
+		var trainX, testX [][]float64
+		var trainY, testY []bool
+		for i, idx := range perm {
+			if i%k == fold {
+				testX, testY = append(testX, x[idx]), append(testY, y[idx])
+			} else {
+				trainX, trainY = append(trainX, x[idx]), append(trainY, y[idx])
+			}
+		}
+
+		clf, err := train(trainX, trainY)
+		if err != nil {
+			return CVResult{}, fmt.Errorf("classifier: 第 %d 折训练失败: %w", fold, err)
+		}
+		scores := make([]float64, len(testX))
+		for i, row := range testX {
+			scores[i] = clf.PredictProba(row)
+		}
+
+		auc := math.NaN()
+		if hasBothClasses(testY) {
+			auc = eval.AUC(eval.ROCFromScores(scores, testY, eval.HigherIsMember))
+			valid = append(valid, auc)
+		}
+		res.FoldAUCs = append(res.FoldAUCs, auc)
+	}
+
+	if len(valid) > 0 {
+		for _, a := range valid {
+			res.MeanAUC += a / float64(len(valid))
+		}
+		for _, a := range valid {
+			res.StdAUC += (a - res.MeanAUC) * (a - res.MeanAUC) / float64(len(valid))
+		}
+		res.StdAUC = math.Sqrt(res.StdAUC)
+	}
+	return res, nil
+}
+
+// ScoreResults 用分类器给审计结果打分：MembershipScore = 成员概率，IsMember = 概率 > 0.5。
+// features 必须与 results 一一对应。
+func ScoreResults(clf Classifier, results []core.AttackResult, features []Features) []core.AttackResult {
+	if len(results) != len(features) {
+		panic("classifier.ScoreResults: 结果与特征长度不一致")
+	}
+
+	out := make([]core.AttackResult, len(results))
+	for i, r := range results {
+		p := clf.PredictProba(features[i].Vector())
+		r.MembershipScore = p
+		r.IsMember = p > 0.5
+		out[i] = r
+	}
+	return out
+}
+
+// savedModel 模型文件的外层结构，Type 决定 Model 的具体类型
+type savedModel struct {
+	Type     string          `json:"type"`
+	Features []string        `json:"features"`
+	Model    json.RawMessage `json:"model"`
+}
+
+// Save 把分类器保存为 JSON 文件
+func Save(path string, clf Classifier) error {
+	var kind string
+	switch clf.(type) {
+	case *Logistic:
+		kind = "logistic"
+	case *BoostedStumps:
+		kind = "boosted_stumps"
+	default:
+		return fmt.Errorf("classifier: 不支持保存 %T", clf)
+	}
+
+	body, err := json.Marshal(clf)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(savedModel{Type: kind, Features: FeatureNames, Model: body}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load 从 JSON 文件读取分类器
+func Load(path string) (Classifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedModel
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("classifier: 解析模型文件 %s 失败: %w", path, err)
+	}
+	if !slices.Equal(saved.Features, FeatureNames) {
+		return nil, fmt.Errorf("classifier: 模型文件的特征 %v 与当前版本 %v 不一致", saved.Features, FeatureNames)
+	}
+
+	var clf Classifier
+	switch saved.Type {
+	case "logistic":
+		clf = &Logistic{}
+	case "boosted_stumps":
+		clf = &BoostedStumps{}
+	default:
+		return nil, fmt.Errorf("classifier: 未知的模型类型 %q", saved.Type)
+	}
+	if err := json.Unmarshal(saved.Model, clf); err != nil {
+		return nil, fmt.Errorf("classifier: 解析模型参数失败: %w", err)
+	}
+	return clf, nil
+}
+
+func hasBothClasses(y []bool) bool {
+	pos := 0
+	for _, m := range y {
+		if m {
+			pos++
+		}
+	}
+	return pos > 0 && pos < len(y)
+}
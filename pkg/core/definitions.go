@@ -30,13 +30,24 @@ type Sample struct {
 
 // AttackResult 存储攻击结果 (用于写入 CSV)
 type AttackResult struct {
-	SampleID      int     // 样本 ID
-	OriginalLabel int     // 原始标签
-	FinalLabel    int     // 攻击后的标签
-	IsSuccess     bool    // 攻击是否成功
-	Queries       int     // 查询次数
-	Distance      float64 // 最终 L2 距离 (MIA 核心指标)
-	IsMember      bool    // 判定结果 (是否为训练集成员)
+	SampleID        int     // 样本 ID
+	OriginalLabel   int     // 原始标签
+	FinalLabel      int     // 攻击后的标签
+	IsSuccess       bool    // 攻击是否成功
+	Queries         int     // 查询次数
+	Distance        float64 // 最终 L2 距离 (MIA 核心指标)
+	IsMember        bool    // 判定结果 (是否为训练集成员)
+	MembershipScore float64 // 校准后的成员分数 (0~1，越大越像成员，由 eval.Threshold 填写)
+	WarmStarted     bool    // 是否使用了热启动缓存中的历史方向初始化
+	DecisionFinal   bool    // 提前停止模式下距离已低于阈值、判定不会再变 (false 表示受预算限制)
+
+	Checkpoints []Checkpoint // 各查询预算下的历史最优距离 (仅在攻击器配置了预算检查点时填写)
+}
+
+// Checkpoint 攻击在某个查询预算内能达到的最优距离
+type Checkpoint struct {
+	Queries  int
+	Distance float64 // 该预算内还没找到对抗样本时为 +Inf
 }
 
 // ==========================================
@@ -49,7 +60,7 @@ type Model interface {
 	// Predict 输入向量，返回 Label。
 	// 错误处理：如果是网络错误，返回 error，Attack 应该处理重试或退出
 	Predict(img Image) (int, error)
-	PredictBatch(imgs []Image) ([]int,error)// 涡轮增压接口
+	PredictBatch(imgs []Image) ([]int, error) // 涡轮增压接口
 	// GetInputSize 返回模型需要的输入维度 (3072)
 	GetInputSize() int
 }
@@ -0,0 +1,89 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// cifarRecordSize CIFAR-10 二进制每条记录的字节数 (1 字节标签 + 3072 字节像素)
+const cifarRecordSize = 1 + core.FlattenedSize
+
+// LoadCifarBatch 读取 CIFAR-10 二进制批次文件 (data_batch_*.bin / test_batch.bin)，
+// 与 LabelScan-Go 的 dataset.CifarLoader 格式一致：像素为 CHW、归一化到 [0, 1]，
+// ID 为文件内序号，Filename 为 "路径_#序号"。limit 为 -1 时读取全部记录。
+func LoadCifarBatch(path string, limit int) ([]core.Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	buffer := make([]byte, cifarRecordSize)
+
+	var samples []core.Sample
+	for limit == -1 || len(samples) < limit {
+		if _, err := io.ReadFull(reader, buffer); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("dataset: %s 第 %d 条记录不完整: %w", path, len(samples), err)
+		}
+
+		pixels := make(core.Image, core.FlattenedSize)
+		for i := range pixels {
+			pixels[i] = float32(buffer[i+1]) / 255.0
+		}
+		samples = append(samples, core.Sample{
+			ID:       len(samples),
+			Data:     pixels,
+			Label:    int(buffer[0]),
+			Filename: fmt.Sprintf("%s_#%d", path, len(samples)),
+		})
+	}
+	return samples, nil
+}
+
+// WriteCifarBatch 按 CIFAR-10 二进制格式 (1 字节标签 + 3072 字节像素) 写出样本，
+// 像素四舍五入到 0~255，可被 LoadCifarBatch 读回
+func WriteCifarBatch(path string, samples []core.Sample) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	record := make([]byte, cifarRecordSize)
+	for _, s := range samples {
+		if s.Label < 0 || s.Label > 255 || len(s.Data) != core.FlattenedSize {
+			return fmt.Errorf("dataset: 样本 %d 无法写成 CIFAR 记录 (标签 %d, 像素数 %d)", s.ID, s.Label, len(s.Data))
+		}
+		record[0] = byte(s.Label)
+		for i, v := range s.Data {
+			record[i+1] = ToByte(v)
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ToByte 把 [0, 1] 的像素值四舍五入为 0~255 (超出范围的截断)。
+// CIFAR 导出、uint8 套接字编码与缩略图共用这一个量化规则。
+func ToByte(v float32) byte {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return byte(v*255 + 0.5)
+}
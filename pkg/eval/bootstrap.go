@@ -0,0 +1,156 @@
+package eval
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// BootstrapConfig 自助法 (Bootstrap) 重采样参数
+type BootstrapConfig struct {
+	Iterations int     // 重采样次数 (0 表示不计算置信区间)
+	Seed       int64   // 随机种子，相同种子得到相同区间
+	Confidence float64 // 置信水平 (默认 0.95)
+}
+
+// Interval 置信区间
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+// String 输出 "[low, high]"
+func (iv Interval) String() string {
+	return fmt.Sprintf("[%.4f, %.4f]", iv.Low, iv.High)
+}
+
+// ReportCI 报告中各指标的置信区间
+type ReportCI struct {
+	Confidence       float64
+	Iterations       int // 实际有效的重采样次数 (只含一类样本的重采样会被丢弃)
+	AUC              Interval
+	BalancedAccuracy Interval
+	Precision        Interval
+	TPRAt01FPR       Interval
+	TPRAt1FPR        Interval
+}
+
+// Bootstrap 对 (结果, 成员真值) 成对有放回重采样，重复计算 Report，
+// 取各指标的百分位区间。
+// 100 个成员 + 100 个非成员时，AUC 0.56 的区间往往跨过 0.5，这正是需要它的原因。
+func Bootstrap(results []core.AttackResult, members []bool, dir Direction, cfg BootstrapConfig) (*ReportCI, error) {
+	intervals, iters, err := resample(results, members, cfg, func(r []core.AttackResult, m []bool) ([]float64, error) {
+		rep, err := Evaluate(r, m, dir)
+		if err != nil {
+			return nil, err
+		}
+		return []float64{rep.AUC, rep.BalancedAccuracy, rep.Precision, rep.TPRAt01FPR, rep.TPRAt1FPR}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportCI{
+		Confidence:       confidenceOrDefault(cfg.Confidence),
+		Iterations:       iters,
+		AUC:              intervals[0],
+		BalancedAccuracy: intervals[1],
+		Precision:        intervals[2],
+		TPRAt01FPR:       intervals[3],
+		TPRAt1FPR:        intervals[4],
+	}, nil
+}
+
+// resample 对 (结果, 成员真值) 成对有放回重采样 cfg.Iterations 次，每次用 stat 计算一组统计量，
+// 返回每个统计量的百分位区间与有效重采样次数。
+// stat 返回错误的重采样 (例如只抽到了一类样本) 会被丢弃；每次返回的统计量个数必须相同。
+func resample(results []core.AttackResult, members []bool, cfg BootstrapConfig,
+	stat func([]core.AttackResult, []bool) ([]float64, error)) ([]Interval, int, error) {
+
+	if len(results) != len(members) {
+		return nil, 0, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	if cfg.Iterations <= 0 {
+		return nil, 0, fmt.Errorf("eval: 自助法重采样次数必须为正数")
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	n := len(results)
+	sampleResults := make([]core.AttackResult, n)
+	sampleMembers := make([]bool, n)
+
+	var values [][]float64 // values[k] 为第 k 个统计量在各次重采样中的取值
+	for i := 0; i < cfg.Iterations; i++ {
+		for j := 0; j < n; j++ {
+			k := rng.Intn(n)
+			sampleResults[j] = results[k]
+			sampleMembers[j] = members[k]
+		}
+
+		stats, err := stat(sampleResults, sampleMembers)
+		if err != nil {
+			continue
+		}
+		if values == nil {
+			values = make([][]float64, len(stats))
+		}
+		for k, v := range stats {
+			values[k] = append(values[k], v)
+		}
+	}
+	if len(values) == 0 || len(values[0]) == 0 {
+		return nil, 0, fmt.Errorf("eval: 所有重采样都无法计算统计量 (例如只包含一类样本)")
+	}
+
+	alpha := (1 - confidenceOrDefault(cfg.Confidence)) / 2
+	intervals := make([]Interval, len(values))
+	for k, v := range values {
+		intervals[k] = percentileInterval(v, alpha)
+	}
+	return intervals, len(values[0]), nil
+}
+
+// confidenceOrDefault 置信水平为 0 时取默认的 0.95
+func confidenceOrDefault(c float64) float64 {
+	if c == 0 {
+		return 0.95
+	}
+	return c
+}
+
+// EvaluateWithCI 计算报告并附上自助法置信区间；cfg.Iterations 为 0 时等同于 Evaluate
+func EvaluateWithCI(results []core.AttackResult, members []bool, dir Direction, cfg BootstrapConfig) (Report, error) {
+	report, err := Evaluate(results, members, dir)
+	if err != nil || cfg.Iterations <= 0 {
+		return report, err
+	}
+
+	ci, err := Bootstrap(results, members, dir, cfg)
+	if err != nil {
+		return report, err
+	}
+	report.CI = ci
+	return report, nil
+}
+
+// percentileInterval 返回 [alpha, 1-alpha] 分位数 (会就地排序 values)
+func percentileInterval(values []float64, alpha float64) Interval {
+	sort.Float64s(values)
+	return Interval{Low: quantile(values, alpha), High: quantile(values, 1-alpha)}
+}
+
+// quantile 对已排序的切片做线性插值分位数
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[lo+1]*frac
+}
@@ -0,0 +1,242 @@
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// BudgetPoint 某个查询预算下的攻击效果
+type BudgetPoint struct {
+	Queries   int
+	AUC       float64
+	TPR       float64 // TPR @ BudgetCurve.TargetFPR
+	Succeeded int     // 该预算内已找到对抗样本的样本数
+
+	AUCCI, TPRCI *Interval // 自助法置信区间 (可选，由 EvaluateBudgetsWithCI 填写)
+}
+
+// BudgetCurve AUC / TPR 随查询预算变化的曲线
+type BudgetCurve struct {
+	TargetFPR float64
+	Points    []BudgetPoint
+}
+
+// AtBudget 取出每条结果在第 k 个检查点时的状态：距离换成该预算内的最优距离，
+// 还没找到对抗样本的记为攻击失败 (Score 为 +Inf)
+func AtBudget(results []core.AttackResult, k int) []core.AttackResult {
+	out := make([]core.AttackResult, len(results))
+	for i, r := range results {
+		cp := r.Checkpoints[k]
+		r.Distance = cp.Distance
+		r.IsSuccess = r.IsSuccess && !math.IsInf(cp.Distance, 1)
+		r.Queries = min(r.Queries, cp.Queries)
+		out[i] = r
+	}
+	return out
+}
+
+// EvaluateBudgets 对攻击时记录的每个预算检查点分别计算 AUC 与 TPR@targetFPR。
+// 所有结果的检查点必须一致 (同一个 HSJAConfig.Checkpoints 跑出来的)。
+// 一次攻击就能回答“1k / 5k / 20k 次查询的攻击者能发现多少泄露”。
+func EvaluateBudgets(results []core.AttackResult, members []bool, dir Direction, targetFPR float64) (*BudgetCurve, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	if len(results) == 0 || len(results[0].Checkpoints) == 0 {
+		return nil, fmt.Errorf("eval: 结果中没有预算检查点 (攻击时需设置 Checkpoints)")
+	}
+	budgets := results[0].Checkpoints
+	for _, r := range results {
+		if len(r.Checkpoints) != len(budgets) {
+			return nil, fmt.Errorf("eval: 样本 %d 的检查点数 %d 与其他样本 (%d) 不一致", r.SampleID, len(r.Checkpoints), len(budgets))
+		}
+		for k, cp := range r.Checkpoints {
+			if cp.Queries != budgets[k].Queries {
+				return nil, fmt.Errorf("eval: 样本 %d 的第 %d 个检查点是 %d 次查询，其他样本是 %d", r.SampleID, k, cp.Queries, budgets[k].Queries)
+			}
+		}
+	}
+
+	curve := &BudgetCurve{TargetFPR: targetFPR}
+	for k, b := range budgets {
+		at := AtBudget(results, k)
+		roc := ROC(at, members, dir)
+		p := BudgetPoint{Queries: b.Queries, AUC: AUC(roc), TPR: TPRAtFPR(roc, targetFPR)}
+		for _, r := range at {
+			if r.IsSuccess {
+				p.Succeeded++
+			}
+		}
+		curve.Points = append(curve.Points, p)
+	}
+	return curve, nil
+}
+
+// EvaluateBudgetsWithCI 同 EvaluateBudgets，并为每个预算下的 AUC 与 TPR 给出自助法置信区间。
+// 同一次重采样同时用于所有预算，区间之间的相关性与真实曲线一致。
+func EvaluateBudgetsWithCI(results []core.AttackResult, members []bool, dir Direction, targetFPR float64, boot BootstrapConfig) (*BudgetCurve, error) {
+	curve, err := EvaluateBudgets(results, members, dir, targetFPR)
+	if err != nil || boot.Iterations <= 0 {
+		return curve, err
+	}
+
+	n := len(curve.Points)
+	intervals, _, err := resample(results, members, boot, func(r []core.AttackResult, m []bool) ([]float64, error) {
+		if pos, neg := countClasses(m); pos == 0 || neg == 0 {
+			return nil, fmt.Errorf("eval: 重采样只包含一类样本")
+		}
+		c, err := EvaluateBudgets(r, m, dir, targetFPR)
+		if err != nil {
+			return nil, err
+		}
+		stats := make([]float64, 2*n)
+		for k, p := range c.Points {
+			stats[k], stats[n+k] = p.AUC, p.TPR
+		}
+		return stats, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for k := range curve.Points {
+		curve.Points[k].AUCCI = &intervals[k]
+		curve.Points[k].TPRCI = &intervals[n+k]
+	}
+	return curve, nil
+}
+
+// hasCI 曲线上是否带有置信区间
+func (c *BudgetCurve) hasCI() bool {
+	return len(c.Points) > 0 && c.Points[0].AUCCI != nil
+}
+
+// String 输出曲线表格
+func (c *BudgetCurve) String() string {
+	var b strings.Builder
+	tprName := fmt.Sprintf("TPR@%g%%", c.TargetFPR*100)
+	if c.hasCI() {
+		fmt.Fprintf(&b, "%10s  %8s  %18s  %12s  %18s  %8s\n", "查询预算", "AUC", "AUC 区间", tprName, "TPR 区间", "已成功")
+		for _, p := range c.Points {
+			fmt.Fprintf(&b, "%10d  %8.4f  %18s  %12.4f  %18s  %8d\n", p.Queries, p.AUC, p.AUCCI, p.TPR, p.TPRCI, p.Succeeded)
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%10s  %8s  %12s  %8s\n", "查询预算", "AUC", tprName, "已成功")
+	for _, p := range c.Points {
+		fmt.Fprintf(&b, "%10d  %8.4f  %12.4f  %8d\n", p.Queries, p.AUC, p.TPR, p.Succeeded)
+	}
+	return b.String()
+}
+
+// WriteBudgetCSV 导出曲线表格
+func WriteBudgetCSV(path string, c *BudgetCurve) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	tprName := fmt.Sprintf("tpr_at_%g_fpr", c.TargetFPR)
+	header := []string{"queries", "auc", tprName, "succeeded"}
+	if c.hasCI() {
+		header = append(header, "auc_low", "auc_high", tprName+"_low", tprName+"_high")
+	}
+	w.Write(header)
+	for _, p := range c.Points {
+		row := []string{
+			fmt.Sprintf("%d", p.Queries),
+			fmt.Sprintf("%.6f", p.AUC),
+			fmt.Sprintf("%.6f", p.TPR),
+			fmt.Sprintf("%d", p.Succeeded),
+		}
+		if c.hasCI() {
+			row = append(row,
+				fmt.Sprintf("%.6f", p.AUCCI.Low), fmt.Sprintf("%.6f", p.AUCCI.High),
+				fmt.Sprintf("%.6f", p.TPRCI.Low), fmt.Sprintf("%.6f", p.TPRCI.High))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteBudgetSVG 把曲线画成 SVG 折线图：横轴为查询预算 (对数刻度)，纵轴为 AUC 与 TPR
+func WriteBudgetSVG(path string, c *BudgetCurve) error {
+	const (
+		width, height = 640, 400
+		left, right   = 60, 20
+		top, bottom   = 30, 50
+	)
+	plotW, plotH := float64(width-left-right), float64(height-top-bottom)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range c.Points {
+		q := math.Log10(math.Max(float64(p.Queries), 1))
+		lo, hi = math.Min(lo, q), math.Max(hi, q)
+	}
+	if hi == lo {
+		lo, hi = lo-1, hi+1
+	}
+	x := func(queries int) float64 {
+		return float64(left) + (math.Log10(math.Max(float64(queries), 1))-lo)/(hi-lo)*plotW
+	}
+	y := func(v float64) float64 {
+		return float64(top) + (1-v)*plotH
+	}
+	line := func(value func(BudgetPoint) float64) string {
+		pts := make([]string, len(c.Points))
+		for i, p := range c.Points {
+			pts[i] = fmt.Sprintf("%.1f,%.1f", x(p.Queries), y(value(p)))
+		}
+		return strings.Join(pts, " ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+
+	// 坐标轴与纵轴刻度
+	fmt.Fprintf(&b, `<polyline points="%d,%d %d,%d %d,%d" fill="none" stroke="black"/>`+"\n",
+		left, top, left, height-bottom, width-right, height-bottom)
+	for _, v := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`+"\n", left, y(v), width-right, y(v))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.2f</text>`+"\n", left-6, y(v)+4, v)
+	}
+	for _, p := range c.Points {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%d</text>`+"\n", x(p.Queries), height-bottom+18, p.Queries)
+	}
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">查询预算</text>`+"\n", float64(left)+plotW/2, height-12)
+
+	// 置信区间画成半透明色带：上界从左到右，下界从右到左，围成一个多边形
+	band := func(ci func(BudgetPoint) *Interval) string {
+		pts := make([]string, 0, 2*len(c.Points))
+		for _, p := range c.Points {
+			pts = append(pts, fmt.Sprintf("%.1f,%.1f", x(p.Queries), y(ci(p).High)))
+		}
+		for i := len(c.Points) - 1; i >= 0; i-- {
+			p := c.Points[i]
+			pts = append(pts, fmt.Sprintf("%.1f,%.1f", x(p.Queries), y(ci(p).Low)))
+		}
+		return strings.Join(pts, " ")
+	}
+	if c.hasCI() {
+		fmt.Fprintf(&b, `<polygon points="%s" fill="steelblue" fill-opacity="0.2" stroke="none"/>`+"\n", band(func(p BudgetPoint) *Interval { return p.AUCCI }))
+		fmt.Fprintf(&b, `<polygon points="%s" fill="firebrick" fill-opacity="0.2" stroke="none"/>`+"\n", band(func(p BudgetPoint) *Interval { return p.TPRCI }))
+	}
+
+	// 随机猜测基线、AUC、TPR
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="gray" stroke-dasharray="4 4"/>`+"\n", left, y(0.5), width-right, y(0.5))
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="steelblue" stroke-width="2"/>`+"\n", line(func(p BudgetPoint) float64 { return p.AUC }))
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="firebrick" stroke-width="2"/>`+"\n", line(func(p BudgetPoint) float64 { return p.TPR }))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="steelblue">AUC</text>`+"\n", left+10, top-10)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="firebrick">TPR@%g%% FPR</text>`+"\n", left+60, top-10, c.TargetFPR*100)
+	b.WriteString("</svg>\n")
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
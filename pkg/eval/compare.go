@@ -0,0 +1,253 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// Comparison 两次审计 (同一批样本) 的泄露程度比较
+// 典型用法: A 为基线模型，B 为加了防御的模型，Diff = B - A。
+type Comparison struct {
+	Total      int
+	Members    int
+	NonMembers int
+	Confidence float64
+
+	// DeLong 检验 (相关 ROC 曲线的 AUC 差异)
+	AUCA, AUCB float64
+	AUCDiff    float64
+	AUCDiffCI  Interval
+	AUCZ       float64
+	AUCPValue  float64
+
+	// 配对自助法 (低 FPR 下的 TPR 差异)
+	TargetFPR  float64
+	TPRA, TPRB float64
+	TPRDiff    float64
+	TPRDiffCI  Interval
+	TPRPValue  float64
+	Iterations int
+}
+
+// PairResults 按 (SampleID, OriginalLabel, 成员真值) 把两份成绩单对齐。
+// LabelScan-Go 里成员和非成员来自不同的批次文件，ID 会重复，所以不能只按 ID 对齐。
+func PairResults(aResults []core.AttackResult, aMembers []bool, bResults []core.AttackResult, bMembers []bool) ([]core.AttackResult, []core.AttackResult, []bool, error) {
+	type key struct {
+		id, label int
+		member    bool
+	}
+
+	index := make(map[key]int, len(bResults))
+	for i, r := range bResults {
+		k := key{r.SampleID, r.OriginalLabel, bMembers[i]}
+		if _, dup := index[k]; dup {
+			return nil, nil, nil, fmt.Errorf("eval: 第二份结果中样本 %+v 重复", k)
+		}
+		index[k] = i
+	}
+
+	var pa, pb []core.AttackResult
+	var members []bool
+	for i, r := range aResults {
+		k := key{r.SampleID, r.OriginalLabel, aMembers[i]}
+		j, ok := index[k]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("eval: 样本 %+v 只出现在第一份结果中", k)
+		}
+		delete(index, k)
+		pa = append(pa, r)
+		pb = append(pb, bResults[j])
+		members = append(members, aMembers[i])
+	}
+	if len(index) > 0 {
+		return nil, nil, nil, fmt.Errorf("eval: 第二份结果中有 %d 个样本不在第一份结果中", len(index))
+	}
+	return pa, pb, members, nil
+}
+
+// Compare 比较同一批样本上的两组攻击结果 (已对齐，members 共用)。
+// AUC 差异使用 DeLong 检验 (DeLong et al., 1988)，考虑了两条 ROC 曲线来自同一批样本的相关性；
+// TPR@targetFPR 没有解析方差，使用配对自助法得到区间和双侧 p 值。
+func Compare(a, b []core.AttackResult, members []bool, dir Direction, targetFPR float64, boot BootstrapConfig) (*Comparison, error) {
+	if len(a) != len(b) || len(a) != len(members) {
+		return nil, fmt.Errorf("eval: 两组结果与成员真值长度不一致 (%d / %d / %d)", len(a), len(b), len(members))
+	}
+	pos, neg := countClasses(members)
+	if pos < 2 || neg < 2 {
+		return nil, fmt.Errorf("eval: DeLong 检验需要至少 2 个成员和 2 个非成员 (成员 %d, 非成员 %d)", pos, neg)
+	}
+	if boot.Iterations <= 0 {
+		boot.Iterations = 1000
+	}
+	if boot.Confidence == 0 {
+		boot.Confidence = 0.95
+	}
+
+	scoresA := orientedScores(a, dir)
+	scoresB := orientedScores(b, dir)
+	zCrit := normalQuantile(1 - (1-boot.Confidence)/2)
+
+	c := &Comparison{
+		Total:      len(members),
+		Members:    pos,
+		NonMembers: neg,
+		Confidence: boot.Confidence,
+		TargetFPR:  targetFPR,
+	}
+
+	// 1. DeLong 检验
+	aucA, aucB, variance := delong(scoresA, scoresB, members)
+	c.AUCA, c.AUCB = aucA, aucB
+	c.AUCDiff = aucB - aucA
+	se := math.Sqrt(variance)
+	c.AUCDiffCI = Interval{Low: c.AUCDiff - zCrit*se, High: c.AUCDiff + zCrit*se}
+	if se > 0 {
+		c.AUCZ = c.AUCDiff / se
+		c.AUCPValue = 2 * (1 - mathutils.NormalCDF(math.Abs(c.AUCZ)))
+	} else {
+		c.AUCPValue = 1
+		if c.AUCDiff != 0 {
+			c.AUCPValue = 0
+		}
+	}
+
+	// 2. 配对自助法：两组结果使用同一组重采样下标
+	c.TPRA = TPRAtFPR(rocFromScores(scoresA, members, HigherIsMember), targetFPR)
+	c.TPRB = TPRAtFPR(rocFromScores(scoresB, members, HigherIsMember), targetFPR)
+	c.TPRDiff = c.TPRB - c.TPRA
+
+	rng := rand.New(rand.NewSource(boot.Seed))
+	n := len(members)
+	sa, sb := make([]float64, n), make([]float64, n)
+	sm := make([]bool, n)
+	var diffs []float64
+	for i := 0; i < boot.Iterations; i++ {
+		for j := 0; j < n; j++ {
+			k := rng.Intn(n)
+			sa[j], sb[j], sm[j] = scoresA[k], scoresB[k], members[k]
+		}
+		if p, q := countClasses(sm); p == 0 || q == 0 {
+			continue
+		}
+		ta := TPRAtFPR(rocFromScores(sa, sm, HigherIsMember), targetFPR)
+		tb := TPRAtFPR(rocFromScores(sb, sm, HigherIsMember), targetFPR)
+		diffs = append(diffs, tb-ta)
+	}
+	if len(diffs) == 0 {
+		return nil, fmt.Errorf("eval: 所有重采样都只包含一类样本")
+	}
+
+	c.Iterations = len(diffs)
+	var below, above int
+	for _, d := range diffs {
+		if d <= 0 {
+			below++
+		}
+		if d >= 0 {
+			above++
+		}
+	}
+	c.TPRPValue = math.Min(1, 2*math.Min(float64(below), float64(above))/float64(len(diffs)))
+	c.TPRDiffCI = percentileInterval(diffs, (1-boot.Confidence)/2)
+	return c, nil
+}
+
+// String 输出比较报告
+func (c *Comparison) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "样本数: %d (成员 %d / 非成员 %d)，差值 = B - A\n", c.Total, c.Members, c.NonMembers)
+	fmt.Fprintf(&b, "AUC:            A %.4f  B %.4f  差值 %+.4f %s  z=%.3f  p=%.4g (DeLong)\n",
+		c.AUCA, c.AUCB, c.AUCDiff, c.AUCDiffCI, c.AUCZ, c.AUCPValue)
+	fmt.Fprintf(&b, "TPR@%g%%FPR:  A %.4f  B %.4f  差值 %+.4f %s  p=%.4g (配对自助法，%d 次)\n",
+		c.TargetFPR*100, c.TPRA, c.TPRB, c.TPRDiff, c.TPRDiffCI, c.TPRPValue, c.Iterations)
+	fmt.Fprintf(&b, "(%.0f%% 置信区间)\n", c.Confidence*100)
+	return b.String()
+}
+
+// delong 返回两组分数的 AUC 以及 AUC_B - AUC_A 的方差
+func delong(scoresA, scoresB []float64, members []bool) (float64, float64, float64) {
+	v10A, v01A := structuralComponents(scoresA, members)
+	v10B, v01B := structuralComponents(scoresB, members)
+
+	aucA, aucB := mathutils.Mean(v10A), mathutils.Mean(v10B)
+	m, n := float64(len(v10A)), float64(len(v01A))
+
+	// S = S10 / m + S01 / n (2x2 协方差矩阵)
+	s10aa, s10bb, s10ab := covariance(v10A, v10A), covariance(v10B, v10B), covariance(v10A, v10B)
+	s01aa, s01bb, s01ab := covariance(v01A, v01A), covariance(v01B, v01B), covariance(v01A, v01B)
+
+	varA := s10aa/m + s01aa/n
+	varB := s10bb/m + s01bb/n
+	cov := s10ab/m + s01ab/n
+	return aucA, aucB, math.Max(varA+varB-2*cov, 0)
+}
+
+// structuralComponents 计算 DeLong 的结构分量:
+// V10[i] = 成员 i 的分数高于非成员的比例，V01[j] = 非成员 j 的分数低于成员的比例 (相等记 0.5)。
+// 对排序后的分数二分查找，复杂度 O((m+n) log(m+n))。
+func structuralComponents(scores []float64, members []bool) ([]float64, []float64) {
+	var pos, neg []float64
+	for i, s := range scores {
+		if members[i] {
+			pos = append(pos, s)
+		} else {
+			neg = append(neg, s)
+		}
+	}
+
+	sortedPos := append([]float64(nil), pos...)
+	sortedNeg := append([]float64(nil), neg...)
+	sort.Float64s(sortedPos)
+	sort.Float64s(sortedNeg)
+
+	// countBelow 返回 sorted 中小于 x 的个数与等于 x 的个数
+	countBelow := func(sorted []float64, x float64) (float64, float64) {
+		lo := sort.SearchFloat64s(sorted, x)
+		hi := sort.Search(len(sorted), func(i int) bool { return sorted[i] > x })
+		return float64(lo), float64(hi - lo)
+	}
+
+	v10 := make([]float64, len(pos))
+	for i, x := range pos {
+		less, equal := countBelow(sortedNeg, x)
+		v10[i] = (less + 0.5*equal) / float64(len(neg))
+	}
+
+	v01 := make([]float64, len(neg))
+	for j, y := range neg {
+		less, equal := countBelow(sortedPos, y)
+		greater := float64(len(pos)) - less - equal
+		v01[j] = (greater + 0.5*equal) / float64(len(pos))
+	}
+	return v10, v01
+}
+
+// orientedScores 返回按方向调整后的分数 (越大越像成员)
+func orientedScores(results []core.AttackResult, dir Direction) []float64 {
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		scores[i] = oriented(Score(r), dir)
+	}
+	return scores
+}
+
+// covariance 样本协方差 (除以 n-1)
+func covariance(x, y []float64) float64 {
+	mx, my := mathutils.Mean(x), mathutils.Mean(y)
+	var sum float64
+	for i := range x {
+		sum += (x[i] - mx) * (y[i] - my)
+	}
+	return sum / float64(len(x)-1)
+}
+
+// normalQuantile 标准正态分布的分位数函数 Φ⁻¹(p)
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
@@ -0,0 +1,129 @@
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// LoadResultsCSV 读取 LabelScan-Go ExportAttackResults 导出的审计成绩单。
+// 列: id, orig, final, success, queries, distance, is_member
+// 其中 is_member 是样本的真实成员身份 (Sample.IsMember)，作为第二个返回值单独给出；
+// 返回的 AttackResult.IsMember 保持 false，留给阈值判定填写。
+// 可选的 dist@<查询数> 列按出现顺序读入 Checkpoints。
+func LoadResultsCSV(path string) ([]core.AttackResult, []bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("eval: %s 为空", path)
+	}
+
+	// 按表头定位列，兼容以后追加的新列
+	col := make(map[string]int)
+	for i, name := range rows[0] {
+		col[name] = i
+	}
+	for _, name := range []string{"id", "orig", "final", "success", "queries", "distance", "is_member"} {
+		if _, ok := col[name]; !ok {
+			return nil, nil, fmt.Errorf("eval: %s 缺少列 %q", path, name)
+		}
+	}
+
+	var cpCols, cpBudgets []int
+	for i, name := range rows[0] {
+		if q, ok := strings.CutPrefix(name, "dist@"); ok {
+			budget, err := strconv.Atoi(q)
+			if err != nil {
+				return nil, nil, fmt.Errorf("eval: %s 的列名 %q 不合法", path, name)
+			}
+			cpCols, cpBudgets = append(cpCols, i), append(cpBudgets, budget)
+		}
+	}
+
+	results := make([]core.AttackResult, 0, len(rows)-1)
+	members := make([]bool, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		var r core.AttackResult
+		var member bool
+		var errs [7]error
+
+		r.SampleID, errs[0] = strconv.Atoi(row[col["id"]])
+		r.OriginalLabel, errs[1] = strconv.Atoi(row[col["orig"]])
+		r.FinalLabel, errs[2] = strconv.Atoi(row[col["final"]])
+		r.IsSuccess, errs[3] = strconv.ParseBool(row[col["success"]])
+		r.Queries, errs[4] = strconv.Atoi(row[col["queries"]])
+		r.Distance, errs[5] = strconv.ParseFloat(row[col["distance"]], 64)
+		member, errs[6] = strconv.ParseBool(row[col["is_member"]])
+
+		for _, e := range errs {
+			if e != nil {
+				return nil, nil, fmt.Errorf("eval: %s 第 %d 行: %w", path, line+2, e)
+			}
+		}
+		for k, c := range cpCols {
+			d, err := strconv.ParseFloat(row[c], 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("eval: %s 第 %d 行: %w", path, line+2, err)
+			}
+			r.Checkpoints = append(r.Checkpoints, core.Checkpoint{Queries: cpBudgets[k], Distance: d})
+		}
+		results = append(results, r)
+		members = append(members, member)
+	}
+	return results, members, nil
+}
+
+// WriteResultsCSV 导出带判定结果的成绩单。
+// 在 ExportAttackResults 的列之后追加 pred_member (阈值判定) 与 membership_score (校准分数)，
+// 结果带预算检查点时再追加 dist@<查询数> 列，因此导出的文件仍可以被 LoadResultsCSV 读回。
+func WriteResultsCSV(path string, results []core.AttackResult, members []bool) error {
+	if len(results) != len(members) {
+		return fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	header := []string{"id", "orig", "final", "success", "queries", "distance", "is_member", "pred_member", "membership_score"}
+	if len(results) > 0 {
+		for _, cp := range results[0].Checkpoints {
+			header = append(header, fmt.Sprintf("dist@%d", cp.Queries))
+		}
+	}
+	w.Write(header)
+	for i, r := range results {
+		row := []string{
+			strconv.Itoa(r.SampleID),
+			strconv.Itoa(r.OriginalLabel),
+			strconv.Itoa(r.FinalLabel),
+			strconv.FormatBool(r.IsSuccess),
+			strconv.Itoa(r.Queries),
+			fmt.Sprintf("%.6f", r.Distance),
+			strconv.FormatBool(members[i]),
+			strconv.FormatBool(r.IsMember),
+			fmt.Sprintf("%.6f", r.MembershipScore),
+		}
+		for _, cp := range r.Checkpoints {
+			row = append(row, fmt.Sprintf("%.6f", cp.Distance))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
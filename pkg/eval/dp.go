@@ -0,0 +1,202 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// EpsilonEstimate 由审计结果得到的差分隐私 ε 经验下界
+type EpsilonEstimate struct {
+	Epsilon    float64 // ε 下界 (以 Confidence 的置信度成立)
+	Delta      float64
+	Confidence float64
+	Threshold  float64   // 取得该下界的判定阈值
+	TPR, FPR   float64   // 该阈值下的点估计
+	TPRLower   float64   // TPR 的 Clopper-Pearson 下界
+	FPRUpper   float64   // FPR 的 Clopper-Pearson 上界
+	EpsilonCI  *Interval // ε 下界的自助法置信区间 (可选，由 EmpiricalEpsilonWithCI 填写)
+}
+
+// EmpiricalEpsilon 把成员推理攻击的效果换算成 (ε, δ)-DP 的经验下界
+// (Jagielski et al., 2020; Nasr et al., 2021)。
+// 若模型满足 (ε, δ)-DP，任何攻击都必须满足 TPR <= e^ε FPR + δ 以及 1-FPR <= e^ε (1-TPR) + δ 的对称形式，
+// 因此 ε >= max{ ln((TPR - δ) / FPR), ln((1 - δ - FPR) / (1 - TPR)) }。
+// 为了让结论以 confidence 的置信度成立，TPR 用 Clopper-Pearson 下界、FPR 用上界 (各分 α/2)。
+// 在所有阈值中取最大的下界；注意阈值是在同一批数据上挑出来的，严格审计时应在独立数据上选阈值。
+func EmpiricalEpsilon(results []core.AttackResult, members []bool, dir Direction, delta, confidence float64) (*EpsilonEstimate, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	pos, neg := countClasses(members)
+	if pos == 0 || neg == 0 {
+		return nil, fmt.Errorf("eval: 需要同时包含成员和非成员 (成员 %d, 非成员 %d)", pos, neg)
+	}
+	if delta < 0 || delta >= 1 {
+		return nil, fmt.Errorf("eval: δ=%g 不在 [0, 1) 内", delta)
+	}
+	if confidence == 0 {
+		confidence = 0.95
+	}
+	alpha := 1 - confidence
+
+	best := &EpsilonEstimate{Delta: delta, Confidence: confidence}
+	for _, p := range ROC(results, members, dir) {
+		tp := int(math.Round(p.TPR * float64(pos)))
+		fp := int(math.Round(p.FPR * float64(neg)))
+		tprLo, _ := clopperPearson(tp, pos, alpha)
+		_, fprHi := clopperPearson(fp, neg, alpha)
+
+		eps := 0.0
+		if tprLo-delta > 0 && fprHi > 0 {
+			eps = math.Max(eps, math.Log((tprLo-delta)/fprHi))
+		}
+		if 1-delta-fprHi > 0 && 1-tprLo > 0 {
+			eps = math.Max(eps, math.Log((1-delta-fprHi)/(1-tprLo)))
+		}
+
+		if eps > best.Epsilon {
+			best.Epsilon = eps
+			best.Threshold = p.Threshold
+			best.TPR, best.FPR = p.TPR, p.FPR
+			best.TPRLower, best.FPRUpper = tprLo, fprHi
+		}
+	}
+	return best, nil
+}
+
+// EmpiricalEpsilonWithCI 同 EmpiricalEpsilon，并用自助法给出 ε 下界本身随审计样本波动的区间。
+// Clopper-Pearson 只覆盖固定阈值下的二项抽样误差，阈值是在同一批数据上挑出来的，
+// 重采样后重新挑阈值得到的区间能反映这部分不确定性。
+func EmpiricalEpsilonWithCI(results []core.AttackResult, members []bool, dir Direction, delta, confidence float64, boot BootstrapConfig) (*EpsilonEstimate, error) {
+	est, err := EmpiricalEpsilon(results, members, dir, delta, confidence)
+	if err != nil || boot.Iterations <= 0 {
+		return est, err
+	}
+
+	intervals, _, err := resample(results, members, boot, func(r []core.AttackResult, m []bool) ([]float64, error) {
+		e, err := EmpiricalEpsilon(r, m, dir, delta, confidence)
+		if err != nil {
+			return nil, err
+		}
+		return []float64{e.Epsilon}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	est.EpsilonCI = &intervals[0]
+	return est, nil
+}
+
+// String 输出 ε 下界报告
+func (e *EpsilonEstimate) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "经验 ε 下界:    %.4f (δ=%g，置信度 %.0f%%)\n", e.Epsilon, e.Delta, e.Confidence*100)
+	if e.EpsilonCI != nil {
+		fmt.Fprintf(&b, "  自助法区间 %s\n", e.EpsilonCI)
+	}
+	if e.Epsilon > 0 {
+		fmt.Fprintf(&b, "  阈值 %.6f: TPR %.4f (下界 %.4f)，FPR %.4f (上界 %.4f)\n",
+			e.Threshold, e.TPR, e.TPRLower, e.FPR, e.FPRUpper)
+	}
+	return b.String()
+}
+
+// clopperPearson 返回 k/n 的 Clopper-Pearson 单侧界 (下界与上界各占 alpha/2)
+func clopperPearson(k, n int, alpha float64) (float64, float64) {
+	lower, upper := 0.0, 1.0
+	if k > 0 {
+		lower = betaQuantile(alpha/2, float64(k), float64(n-k+1))
+	}
+	if k < n {
+		upper = betaQuantile(1-alpha/2, float64(k+1), float64(n-k))
+	}
+	return lower, upper
+}
+
+// betaQuantile Beta(a, b) 分布的分位数，对正则化不完全 Beta 函数做二分
+func betaQuantile(p, a, b float64) float64 {
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if regIncBeta(mid, a, b) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// regIncBeta 正则化不完全 Beta 函数 I_x(a, b)
+// 对应 Python: scipy.special.betainc(a, b, x)，连分式展开 (Numerical Recipes 6.4)
+func regIncBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	// 利用对称性保证连分式收敛
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	qab, qap, qam := a+b, a+1, a-1
+
+	c, d := 1.0, 1-qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		// 偶数项
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// 奇数项
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < 1e-14 {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,234 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// Alternative 备择假设的方向
+type Alternative int
+
+const (
+	// Greater 第一组大于第二组 (例如: 可疑集的边界距离大于未见集)
+	Greater Alternative = iota
+	// Less 第一组小于第二组
+	Less
+	// TwoSided 两组不相等
+	TwoSided
+)
+
+// TestResult 假设检验结果
+type TestResult struct {
+	Statistic float64 // t 统计量，或 Mann-Whitney / Wilcoxon 的 z 值
+	DF        float64 // 自由度 (仅 t 检验)
+	PValue    float64
+}
+
+// WelchTTest Welch t 检验 (不假设方差相等)。
+// 对应 Python: scipy.stats.ttest_ind(a, b, equal_var=False, alternative=...)
+func WelchTTest(a, b []float64, alt Alternative) (TestResult, error) {
+	if len(a) < 2 || len(b) < 2 {
+		return TestResult{}, fmt.Errorf("eval: Welch t 检验每组至少需要 2 个值 (%d / %d)", len(a), len(b))
+	}
+
+	ma, mb := mathutils.Mean(a), mathutils.Mean(b)
+	va, vb := covariance(a, a), covariance(b, b)
+	na, nb := float64(len(a)), float64(len(b))
+
+	se2 := va/na + vb/nb
+	if se2 == 0 {
+		// 两组都是常数：只能比较均值本身
+		t := 0.0
+		if ma != mb {
+			t = math.Copysign(math.Inf(1), ma-mb)
+		}
+		return TestResult{Statistic: t, PValue: tailP(t, alt, mathutils.NormalCDF)}, nil
+	}
+
+	t := (ma - mb) / math.Sqrt(se2)
+	df := se2 * se2 / ((va/na)*(va/na)/(na-1) + (vb/nb)*(vb/nb)/(nb-1))
+	p := tailP(t, alt, func(x float64) float64 { return studentTCDF(x, df) })
+	return TestResult{Statistic: t, DF: df, PValue: p}, nil
+}
+
+// MannWhitneyU Mann-Whitney U 检验 (秩和检验)，正态近似 + 结校正 + 连续性校正。
+// 对应 Python: scipy.stats.mannwhitneyu(a, b, alternative=..., method="asymptotic")
+// 只依赖秩，因此可以直接处理攻击失败时的 +Inf 距离。
+func MannWhitneyU(a, b []float64, alt Alternative) (TestResult, error) {
+	if len(a) == 0 || len(b) == 0 {
+		return TestResult{}, fmt.Errorf("eval: Mann-Whitney 检验两组都不能为空 (%d / %d)", len(a), len(b))
+	}
+
+	type item struct {
+		v     float64
+		first bool
+	}
+	all := make([]item, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, item{v, true})
+	}
+	for _, v := range b {
+		all = append(all, item{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// 平均秩，并累计结校正项 Σ(t³ - t)
+	var rankSumA, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		avgRank := float64(i+j+1) / 2 // 秩从 1 开始
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSumA += avgRank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	na, nb := float64(len(a)), float64(len(b))
+	n := na + nb
+	u := rankSumA - na*(na+1)/2
+	mu := na * nb / 2
+	sigma := math.Sqrt(na * nb / 12 * ((n + 1) - tieTerm/(n*(n-1))))
+	if sigma == 0 {
+		return TestResult{Statistic: 0, PValue: 1}, nil
+	}
+
+	// 连续性校正：向均值方向收缩 0.5
+	var z float64
+	switch alt {
+	case Greater:
+		z = (u - mu - 0.5) / sigma
+	case Less:
+		z = (u - mu + 0.5) / sigma
+	default:
+		z = (math.Abs(u-mu) - 0.5) / sigma
+		z = math.Max(z, 0)
+	}
+	return TestResult{Statistic: z, PValue: tailP(z, alt, mathutils.NormalCDF)}, nil
+}
+
+// PairedTTest 配对 t 检验：对逐样本差值 a[i] - b[i] 做单样本 t 检验。
+// 对应 Python: scipy.stats.ttest_rel(a, b, alternative=...)
+func PairedTTest(a, b []float64, alt Alternative) (TestResult, error) {
+	if len(a) != len(b) {
+		return TestResult{}, fmt.Errorf("eval: 配对 t 检验两组长度不一致 (%d / %d)", len(a), len(b))
+	}
+	if len(a) < 2 {
+		return TestResult{}, fmt.Errorf("eval: 配对 t 检验至少需要 2 对值 (%d)", len(a))
+	}
+
+	diffs := make([]float64, len(a))
+	for i := range a {
+		diffs[i] = a[i] - b[i]
+	}
+	md, vd := mathutils.Mean(diffs), covariance(diffs, diffs)
+	n := float64(len(diffs))
+
+	if vd == 0 {
+		// 差值全部相同：只能看差值本身是否为 0
+		t := 0.0
+		if md != 0 {
+			t = math.Copysign(math.Inf(1), md)
+		}
+		return TestResult{Statistic: t, DF: n - 1, PValue: tailP(t, alt, mathutils.NormalCDF)}, nil
+	}
+
+	t := md / math.Sqrt(vd/n)
+	p := tailP(t, alt, func(x float64) float64 { return studentTCDF(x, n-1) })
+	return TestResult{Statistic: t, DF: n - 1, PValue: p}, nil
+}
+
+// WilcoxonSignedRank Wilcoxon 符号秩检验：对逐样本差值 a[i] - b[i] 的符号与秩做检验，
+// 丢弃差值为 0 的对，正态近似 + 结校正 + 连续性校正 (与 MannWhitneyU 相同，向均值方向收缩 0.5)。
+// 对应 Python: scipy.stats.wilcoxon(a, b, alternative=..., method="approx", correction=True)
+func WilcoxonSignedRank(a, b []float64, alt Alternative) (TestResult, error) {
+	if len(a) != len(b) {
+		return TestResult{}, fmt.Errorf("eval: Wilcoxon 检验两组长度不一致 (%d / %d)", len(a), len(b))
+	}
+	if len(a) == 0 {
+		return TestResult{}, fmt.Errorf("eval: Wilcoxon 检验不能为空")
+	}
+
+	var diffs []float64
+	for i := range a {
+		if d := a[i] - b[i]; d != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	if len(diffs) == 0 {
+		return TestResult{Statistic: 0, PValue: 1}, nil
+	}
+	sort.Slice(diffs, func(i, j int) bool { return math.Abs(diffs[i]) < math.Abs(diffs[j]) })
+
+	// 按 |d| 的平均秩累计正差值的秩和 T+，并累计结校正项 Σ(t³ - t)
+	var rankSumPos, tieTerm float64
+	for i := 0; i < len(diffs); {
+		j := i
+		for j < len(diffs) && math.Abs(diffs[j]) == math.Abs(diffs[i]) {
+			j++
+		}
+		avgRank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if diffs[k] > 0 {
+				rankSumPos += avgRank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	n := float64(len(diffs))
+	mu := n * (n + 1) / 4
+	sigma := math.Sqrt(n*(n+1)*(2*n+1)/24 - tieTerm/48)
+	if sigma == 0 {
+		return TestResult{Statistic: 0, PValue: 1}, nil
+	}
+
+	var z float64
+	switch alt {
+	case Greater:
+		z = (rankSumPos - mu - 0.5) / sigma
+	case Less:
+		z = (rankSumPos - mu + 0.5) / sigma
+	default:
+		z = math.Max((math.Abs(rankSumPos-mu)-0.5)/sigma, 0)
+	}
+	return TestResult{Statistic: z, PValue: tailP(z, alt, mathutils.NormalCDF)}, nil
+}
+
+// tailP 由统计量和分布函数求 p 值
+func tailP(stat float64, alt Alternative, cdf func(float64) float64) float64 {
+	switch alt {
+	case Greater:
+		return 1 - cdf(stat)
+	case Less:
+		return cdf(stat)
+	}
+	return math.Min(1, 2*(1-cdf(math.Abs(stat))))
+}
+
+// studentTCDF 自由度为 df 的 t 分布的分布函数
+func studentTCDF(t, df float64) float64 {
+	if math.IsInf(t, 1) {
+		return 1
+	}
+	if math.IsInf(t, -1) {
+		return 0
+	}
+	x := df / (df + t*t)
+	tail := 0.5 * regIncBeta(x, df/2, 0.5) // P(T > |t|)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
@@ -0,0 +1,189 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// Judge 能给攻击结果填写成员判定的阈值 (全局 Threshold 或按类别的 ClassThresholds)
+type Judge interface {
+	Apply(results []core.AttackResult) []core.AttackResult
+	Save(path string) error
+}
+
+// Calibrator 在一组带标签的结果上求阈值的函数
+type Calibrator func(results []core.AttackResult, members []bool) (*Threshold, error)
+
+// MaxAccuracyCalibrator 返回 max_accuracy 策略的校准函数
+func MaxAccuracyCalibrator(dir Direction) Calibrator {
+	return func(results []core.AttackResult, members []bool) (*Threshold, error) {
+		return CalibrateMaxAccuracy(results, members, dir)
+	}
+}
+
+// FixedFPRCalibrator 返回 fixed_fpr 策略的校准函数 (自动筛出非成员)
+func FixedFPRCalibrator(targetFPR float64, dir Direction) Calibrator {
+	return func(results []core.AttackResult, members []bool) (*Threshold, error) {
+		var nonMembers []core.AttackResult
+		for i, r := range results {
+			if !members[i] {
+				nonMembers = append(nonMembers, r)
+			}
+		}
+		return CalibrateFixedFPR(nonMembers, targetFPR, dir)
+	}
+}
+
+// ClassThresholds 按原始标签 (OriginalLabel) 分别校准的阈值
+// CIFAR 各类别离边界的远近差别很大 (猫狗互相贴得很近，卡车离得很远)，
+// 全局阈值会把“类别难度”和“成员身份”混在一起。
+// 校准数据不足的类别回退到全局阈值。
+type ClassThresholds struct {
+	Global   *Threshold         `json:"global"`
+	PerClass map[int]*Threshold `json:"per_class"`
+}
+
+// CalibratePerClass 对每个类别分别调用 calibrate；
+// 某类别样本数少于 minPerClass 或校准失败 (例如缺少成员) 时，该类别使用全局阈值。
+func CalibratePerClass(results []core.AttackResult, members []bool, calibrate Calibrator, minPerClass int) (*ClassThresholds, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+
+	global, err := calibrate(results, members)
+	if err != nil {
+		return nil, err
+	}
+
+	ct := &ClassThresholds{Global: global, PerClass: make(map[int]*Threshold)}
+	for label, idx := range groupByClass(results) {
+		if len(idx) < minPerClass {
+			continue
+		}
+		subResults, subMembers := subset(results, members, idx)
+		if t, err := calibrate(subResults, subMembers); err == nil {
+			ct.PerClass[label] = t
+		}
+	}
+	return ct, nil
+}
+
+// For 返回某个类别应使用的阈值
+func (ct *ClassThresholds) For(label int) *Threshold {
+	if t, ok := ct.PerClass[label]; ok {
+		return t
+	}
+	return ct.Global
+}
+
+// Apply 按每条结果的 OriginalLabel 选择阈值，填写 IsMember 与 MembershipScore
+func (ct *ClassThresholds) Apply(results []core.AttackResult) []core.AttackResult {
+	out := make([]core.AttackResult, len(results))
+	for i, r := range results {
+		t := ct.For(r.OriginalLabel)
+		r.IsMember = t.IsMember(r)
+		r.MembershipScore = t.MembershipScore(r)
+		out[i] = r
+	}
+	return out
+}
+
+// Save 把按类别的阈值保存为 JSON 文件
+func (ct *ClassThresholds) Save(path string) error {
+	data, err := json.MarshalIndent(ct, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadClassThresholds 从 JSON 文件读取按类别的阈值
+func LoadClassThresholds(path string) (*ClassThresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ct ClassThresholds
+	if err := json.Unmarshal(data, &ct); err != nil {
+		return nil, fmt.Errorf("eval: 解析阈值文件 %s 失败: %w", path, err)
+	}
+	if ct.Global == nil {
+		return nil, fmt.Errorf("eval: %s 缺少全局阈值", path)
+	}
+	return &ct, nil
+}
+
+// ClassReport 单个类别的评估结果
+type ClassReport struct {
+	Label  int
+	Report Report
+}
+
+// EvaluateByClass 按 OriginalLabel 分组计算指标，按 AUC 由高到低排序 (泄露最严重的类别在前)。
+// 缺少成员或非成员的类别无法计算 AUC，会被跳过。
+// boot.Iterations > 0 时每个类别都附带自助法置信区间。
+func EvaluateByClass(results []core.AttackResult, members []bool, dir Direction, boot BootstrapConfig) ([]ClassReport, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+
+	var reports []ClassReport
+	for label, idx := range groupByClass(results) {
+		subResults, subMembers := subset(results, members, idx)
+		r, err := EvaluateWithCI(subResults, subMembers, dir, boot)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, ClassReport{Label: label, Report: r})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Report.AUC != reports[j].Report.AUC {
+			return reports[i].Report.AUC > reports[j].Report.AUC
+		}
+		return reports[i].Label < reports[j].Label
+	})
+	return reports, nil
+}
+
+// FormatClassReports 把按类别的评估结果排成表格
+func FormatClassReports(reports []ClassReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-6s %-6s %-6s %-8s %-18s %-8s %-12s %-10s\n",
+		"label", "成员", "非成员", "AUC", "AUC 置信区间", "平衡准确率", "TPR@0.1%FPR", "TPR@1%FPR")
+	for _, c := range reports {
+		r := c.Report
+		ci := "-"
+		if r.CI != nil {
+			ci = r.CI.AUC.String()
+		}
+		fmt.Fprintf(&b, "%-6d %-6d %-6d %-8.4f %-18s %-8.4f %-12.4f %-10.4f\n",
+			c.Label, r.Members, r.NonMembers, r.AUC, ci, r.BalancedAccuracy, r.TPRAt01FPR, r.TPRAt1FPR)
+	}
+	return b.String()
+}
+
+// groupByClass 返回每个 OriginalLabel 对应的下标
+func groupByClass(results []core.AttackResult) map[int][]int {
+	groups := make(map[int][]int)
+	for i, r := range results {
+		groups[r.OriginalLabel] = append(groups[r.OriginalLabel], i)
+	}
+	return groups
+}
+
+// subset 按下标取出结果与成员真值
+func subset(results []core.AttackResult, members []bool, idx []int) ([]core.AttackResult, []bool) {
+	subResults := make([]core.AttackResult, len(idx))
+	subMembers := make([]bool, len(idx))
+	for i, j := range idx {
+		subResults[i] = results[j]
+		subMembers[i] = members[j]
+	}
+	return subResults, subMembers
+}
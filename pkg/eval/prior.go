@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// PriorPoint 某个阈值在给定成员先验下的表现
+type PriorPoint struct {
+	Threshold float64
+	Recall    float64 // = TPR，与先验无关
+	FPR       float64
+	Precision float64 // 在审计集自身的成员比例下测得的精确率
+	PPV       float64 // 成员占总体 Prior 时的期望精确率 (阳性预测值)
+}
+
+// PriorReport 一个成员先验下的全部阈值表现
+type PriorReport struct {
+	Prior     float64
+	MinRecall float64
+	Points    []PriorPoint
+	Best      PriorPoint // 召回率 >= MinRecall 时 PPV 最高的阈值
+	HasBest   bool       // 没有任何阈值达到 MinRecall 时为 false
+	BestCI    *Interval  // 最高 PPV 的自助法置信区间 (可选，由 PrecisionAtPriorWithCI 填写)
+}
+
+// PrecisionAtPrior 计算成员只占总体 prior 比例时，每个阈值的期望精确率。
+// 平衡的 100/100 审计集上 AUC 看起来不错，但当成员只占 1% 时，
+// PPV = π·TPR / (π·TPR + (1-π)·FPR) 往往会掉到很低，合规评审最关心的就是这个数。
+func PrecisionAtPrior(results []core.AttackResult, members []bool, dir Direction, prior, minRecall float64) (*PriorReport, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	if prior <= 0 || prior >= 1 {
+		return nil, fmt.Errorf("eval: 成员先验 %g 不在 (0, 1) 内", prior)
+	}
+	pos, neg := countClasses(members)
+	if pos == 0 || neg == 0 {
+		return nil, fmt.Errorf("eval: 需要同时包含成员和非成员 (成员 %d, 非成员 %d)", pos, neg)
+	}
+
+	report := &PriorReport{Prior: prior, MinRecall: minRecall}
+	for _, p := range ROC(results, members, dir) {
+		if p.TPR == 0 && p.FPR == 0 {
+			continue // 起点：没有任何样本被判为成员，精确率无定义
+		}
+
+		tp, fp := p.TPR*float64(pos), p.FPR*float64(neg)
+		point := PriorPoint{
+			Threshold: p.Threshold,
+			Recall:    p.TPR,
+			FPR:       p.FPR,
+			Precision: tp / (tp + fp),
+			PPV:       prior * p.TPR / (prior*p.TPR + (1-prior)*p.FPR),
+		}
+		report.Points = append(report.Points, point)
+
+		if point.Recall >= minRecall && (!report.HasBest || point.PPV > report.Best.PPV) {
+			report.Best = point
+			report.HasBest = true
+		}
+	}
+	return report, nil
+}
+
+// PrecisionAtPriorWithCI 同 PrecisionAtPrior，并用自助法给出“召回率 >= minRecall 时最高 PPV”的置信区间。
+// 每次重采样都重新挑选阈值，区间因此包含了挑阈值本身带来的波动。
+func PrecisionAtPriorWithCI(results []core.AttackResult, members []bool, dir Direction, prior, minRecall float64, boot BootstrapConfig) (*PriorReport, error) {
+	report, err := PrecisionAtPrior(results, members, dir, prior, minRecall)
+	if err != nil {
+		return nil, err
+	}
+	if !report.HasBest || boot.Iterations <= 0 {
+		return report, nil
+	}
+
+	intervals, _, err := resample(results, members, boot, func(r []core.AttackResult, m []bool) ([]float64, error) {
+		rep, err := PrecisionAtPrior(r, m, dir, prior, minRecall)
+		if err != nil {
+			return nil, err
+		}
+		if !rep.HasBest {
+			return []float64{0}, nil // 达不到召回率要求时按 PPV 为 0 计，区间随之变宽
+		}
+		return []float64{rep.Best.PPV}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.BestCI = &intervals[0]
+	return report, nil
+}
+
+// String 输出该先验下的摘要
+func (r *PriorReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "成员先验 %g%%: ", r.Prior*100)
+	if !r.HasBest {
+		fmt.Fprintf(&b, "没有阈值能达到召回率 %.4f\n", r.MinRecall)
+		return b.String()
+	}
+	best := r.Best
+	fmt.Fprintf(&b, "召回率 >= %.4f 时最高 PPV %.4f (阈值 %.6f，召回率 %.4f，FPR %.4f，审计集精确率 %.4f)\n",
+		r.MinRecall, best.PPV, best.Threshold, best.Recall, best.FPR, best.Precision)
+	if r.BestCI != nil {
+		fmt.Fprintf(&b, "  最高 PPV 置信区间 %s\n", r.BestCI)
+	}
+	return b.String()
+}
+
+// WritePriorCSV 把多个先验下的逐阈值表格导出为一个 CSV
+func WritePriorCSV(path string, reports []*PriorReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"prior", "threshold", "recall", "fpr", "precision", "ppv"})
+	for _, r := range reports {
+		for _, p := range r.Points {
+			w.Write([]string{
+				fmt.Sprintf("%g", r.Prior),
+				fmt.Sprintf("%.6f", p.Threshold),
+				fmt.Sprintf("%.6f", p.Recall),
+				fmt.Sprintf("%.6f", p.FPR),
+				fmt.Sprintf("%.6f", p.Precision),
+				fmt.Sprintf("%.6f", p.PPV),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
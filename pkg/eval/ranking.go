@@ -0,0 +1,192 @@
+package eval
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/dataset"
+)
+
+// RankedSample 脆弱性排名中的一条记录
+type RankedSample struct {
+	Rank           int     `json:"rank"` // 1 = 最容易被识别为成员
+	SampleID       int     `json:"sample_id"`
+	Filename       string  `json:"filename"`
+	Label          int     `json:"label"`
+	FinalLabel     int     `json:"final_label"`
+	IsMember       bool    `json:"is_member"`
+	Score          float64 `json:"score"`           // 校准后的成员分数 (MembershipScore)
+	Distance       float64 `json:"distance"`        // 边界距离
+	RiskPercentile float64 `json:"risk_percentile"` // 分数不高于该样本的比例 (0~100)
+	Thumbnail      string  `json:"thumbnail,omitempty"`
+}
+
+// RankVulnerability 按校准后的成员分数从高到低给审计样本排名，并给出风险百分位。
+// results 必须已经过 Judge.Apply (或 classifier / lira 的 ScoreResults) 填写 MembershipScore。
+// samples 可选 (sampleMembers 为其成员真值)，按 (SampleID, 成员真值) 对应上原样本以带出 Filename，
+// 方便数据所有者追溯到具体记录。成员和非成员来自不同的批次文件，ID 会重复，所以不能只按 ID 对应。
+func RankVulnerability(results []core.AttackResult, members []bool, samples []core.Sample, sampleMembers []bool) ([]RankedSample, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	bySample, err := indexSamples(samples, sampleMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]RankedSample, len(results))
+	for i, r := range results {
+		ranked[i] = RankedSample{
+			SampleID:   r.SampleID,
+			Filename:   bySample[sampleKey{r.SampleID, members[i]}].Filename,
+			Label:      r.OriginalLabel,
+			FinalLabel: r.FinalLabel,
+			IsMember:   members[i],
+			Score:      r.MembershipScore,
+			Distance:   r.Distance,
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].SampleID < ranked[j].SampleID
+	})
+
+	// 百分位：并列的样本取相同的值 (分数不高于它的样本数 / 总数)
+	n := len(ranked)
+	for i := 0; i < n; {
+		j := i
+		for j < n && ranked[j].Score == ranked[i].Score {
+			j++
+		}
+		pct := 100 * float64(n-i) / float64(n)
+		for k := i; k < j; k++ {
+			ranked[k].Rank = k + 1
+			ranked[k].RiskPercentile = pct
+		}
+		i = j
+	}
+	return ranked, nil
+}
+
+// WriteThumbnails 把排名中样本的图片写成 PNG 缩略图 (放大 4 倍) 并填写 Thumbnail 路径。
+// 原样本同样按 (SampleID, 成员真值) 查找，找不到的记录保持 Thumbnail 为空。
+func WriteThumbnails(dir string, ranked []RankedSample, samples []core.Sample, sampleMembers []bool) error {
+	const scale = 4
+
+	bySample, err := indexSamples(samples, sampleMembers)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for i := range ranked {
+		s, ok := bySample[sampleKey{ranked[i].SampleID, ranked[i].IsMember}]
+		if !ok || len(s.Data) != core.FlattenedSize {
+			continue
+		}
+
+		img := image.NewRGBA(image.Rect(0, 0, core.ImgWidth*scale, core.ImgHeight*scale))
+		for y := 0; y < core.ImgHeight*scale; y++ {
+			for x := 0; x < core.ImgWidth*scale; x++ {
+				p := (y/scale)*core.ImgWidth + x/scale // CHW 布局
+				img.Set(x, y, color.RGBA{
+					R: dataset.ToByte(s.Data[p]),
+					G: dataset.ToByte(s.Data[core.ImgHeight*core.ImgWidth+p]),
+					B: dataset.ToByte(s.Data[2*core.ImgHeight*core.ImgWidth+p]),
+					A: 255,
+				})
+			}
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("rank%04d_id%d.png", ranked[i].Rank, ranked[i].SampleID))
+		if err := writePNG(path, img); err != nil {
+			return err
+		}
+		ranked[i].Thumbnail = path
+	}
+	return nil
+}
+
+// sampleKey 审计样本的唯一标识：成员和非成员各自从 0 编号
+type sampleKey struct {
+	id     int
+	member bool
+}
+
+// indexSamples 按 (ID, 成员真值) 建立原样本索引
+func indexSamples(samples []core.Sample, sampleMembers []bool) (map[sampleKey]core.Sample, error) {
+	if len(samples) != len(sampleMembers) {
+		return nil, fmt.Errorf("eval: %d 个样本与 %d 个成员真值不对应", len(samples), len(sampleMembers))
+	}
+	index := make(map[sampleKey]core.Sample, len(samples))
+	for i, s := range samples {
+		k := sampleKey{s.ID, sampleMembers[i]}
+		if _, dup := index[k]; dup {
+			return nil, fmt.Errorf("eval: 样本 %+v 重复", k)
+		}
+		index[k] = s
+	}
+	return index, nil
+}
+
+// WriteRankingJSON 把排名 (通常是前 N 个) 导出为 JSON 档案
+func WriteRankingJSON(path string, ranked []RankedSample) error {
+	data, err := json.MarshalIndent(ranked, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// WriteRankingCSV 把排名 (通常是前 N 个) 导出为 CSV 档案
+func WriteRankingCSV(path string, ranked []RankedSample) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"rank", "id", "filename", "label", "final_label", "is_member", "score", "distance", "risk_percentile", "thumbnail"})
+	for _, r := range ranked {
+		w.Write([]string{
+			strconv.Itoa(r.Rank),
+			strconv.Itoa(r.SampleID),
+			r.Filename,
+			strconv.Itoa(r.Label),
+			strconv.Itoa(r.FinalLabel),
+			strconv.FormatBool(r.IsMember),
+			fmt.Sprintf("%.6f", r.Score),
+			fmt.Sprintf("%.6f", r.Distance),
+			fmt.Sprintf("%.2f", r.RiskPercentile),
+			r.Thumbnail,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
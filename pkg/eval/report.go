@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// Report 一次审计的成员推理指标汇总 (MIA 论文必报的那几项)
+type Report struct {
+	Total            int     // 样本总数
+	Members          int     // 成员数
+	NonMembers       int     // 非成员数
+	AUC              float64 // ROC 曲线下面积
+	BalancedAccuracy float64 // 最优阈值下的平衡准确率
+	BestThreshold    float64 // 平衡准确率最优时的阈值
+	Precision        float64 // 最优阈值下的精确率 TP / (TP + FP)
+	TPRAt01FPR       float64 // TPR @ 0.1% FPR
+	TPRAt1FPR        float64 // TPR @ 1% FPR
+	Direction        Direction
+	CI               *ReportCI // 自助法置信区间 (可选，由 Bootstrap 填写)
+}
+
+// Evaluate 计算 ROC 相关的全部指标
+// members 必须与 results 一一对应；成员或非成员为空时返回错误。
+func Evaluate(results []core.AttackResult, members []bool, dir Direction) (Report, error) {
+	if len(results) != len(members) {
+		return Report{}, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	pos, neg := countClasses(members)
+	if pos == 0 || neg == 0 {
+		return Report{}, fmt.Errorf("eval: 需要同时包含成员和非成员 (成员 %d, 非成员 %d)", pos, neg)
+	}
+
+	curve := ROC(results, members, dir)
+	best := bestBalancedPoint(curve)
+	acc := (best.TPR + 1 - best.FPR) / 2
+
+	precision := 0.0
+	tp, fp := best.TPR*float64(pos), best.FPR*float64(neg)
+	if tp+fp > 0 {
+		precision = tp / (tp + fp)
+	}
+
+	return Report{
+		Total:            len(results),
+		Members:          pos,
+		NonMembers:       neg,
+		AUC:              AUC(curve),
+		BalancedAccuracy: acc,
+		BestThreshold:    best.Threshold,
+		Precision:        precision,
+		TPRAt01FPR:       TPRAtFPR(curve, 0.001),
+		TPRAt1FPR:        TPRAtFPR(curve, 0.01),
+		Direction:        dir,
+	}, nil
+}
+
+// String 以人类可读的表格形式输出报告
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "样本数: %d (成员 %d / 非成员 %d)\n", r.Total, r.Members, r.NonMembers)
+	if r.CI == nil {
+		fmt.Fprintf(&b, "AUC:            %.4f\n", r.AUC)
+		fmt.Fprintf(&b, "平衡准确率:     %.4f (阈值 %.6f)\n", r.BalancedAccuracy, r.BestThreshold)
+		fmt.Fprintf(&b, "精确率:         %.4f\n", r.Precision)
+		fmt.Fprintf(&b, "TPR@0.1%%FPR:    %.4f\n", r.TPRAt01FPR)
+		fmt.Fprintf(&b, "TPR@1%%FPR:      %.4f\n", r.TPRAt1FPR)
+		return b.String()
+	}
+
+	ci := r.CI
+	fmt.Fprintf(&b, "AUC:            %.4f %s\n", r.AUC, ci.AUC)
+	fmt.Fprintf(&b, "平衡准确率:     %.4f %s (阈值 %.6f)\n", r.BalancedAccuracy, ci.BalancedAccuracy, r.BestThreshold)
+	fmt.Fprintf(&b, "精确率:         %.4f %s\n", r.Precision, ci.Precision)
+	fmt.Fprintf(&b, "TPR@0.1%%FPR:    %.4f %s\n", r.TPRAt01FPR, ci.TPRAt01FPR)
+	fmt.Fprintf(&b, "TPR@1%%FPR:      %.4f %s\n", r.TPRAt1FPR, ci.TPRAt1FPR)
+	fmt.Fprintf(&b, "(%.0f%% 自助法置信区间，%d 次重采样)\n", ci.Confidence*100, ci.Iterations)
+	return b.String()
+}
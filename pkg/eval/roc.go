@@ -0,0 +1,166 @@
+package eval
+
+import (
+	"math"
+	"sort"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// ============================================================================
+// 成员推理评估工具库 (Membership Evaluation)
+// 对应 Python 库: sklearn.metrics (roc_curve, auc)
+// 输入统一为 []core.AttackResult + 与之一一对应的成员真值 []bool
+// (即 LabelScan-Go 中 Sample.IsMember 的值)。
+// ============================================================================
+
+// Direction 分数方向：多大的分数才更像成员
+type Direction int
+
+const (
+	// HigherIsMember 分数越大越可能是成员。
+	// 边界距离的常规方向：训练样本离决策边界更远。
+	HigherIsMember Direction = iota
+	// LowerIsMember 分数越小越可能是成员 (例如 LiRA 之外的某些损失类分数)。
+	LowerIsMember
+)
+
+// ROCPoint ROC 曲线上的一个点
+// 判定规则: 分数 >= Threshold (按 Direction 调整后) 即判为成员。
+type ROCPoint struct {
+	Threshold float64 // 原始分数尺度上的阈值
+	FPR       float64 // 假阳性率 (非成员被判为成员的比例)
+	TPR       float64 // 真阳性率 (成员被判为成员的比例)
+}
+
+// Score 返回一条攻击结果的原始成员分数 (即 Distance)。
+// 攻击失败 (连初始对抗样本都找不到) 说明样本极其稳健，记为 +Inf。
+func Score(r core.AttackResult) float64 {
+	if !r.IsSuccess {
+		return math.Inf(1)
+	}
+	return r.Distance
+}
+
+// oriented 把原始分数转换成“越大越像成员”的方向
+func oriented(score float64, dir Direction) float64 {
+	if dir == LowerIsMember {
+		return -score
+	}
+	return score
+}
+
+// ROC 计算 ROC 曲线。
+// 对应 Python: sklearn.metrics.roc_curve(members, scores)
+// 返回的曲线从 (0,0) 开始、到 (1,1) 结束，相同分数合并为一个点。
+func ROC(results []core.AttackResult, members []bool, dir Direction) []ROCPoint {
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		scores[i] = Score(r)
+	}
+	return rocFromScores(scores, members, dir)
+}
+
+// ROCFromScores 在任意分数上计算 ROC 曲线 (例如学习得到的成员概率)
+func ROCFromScores(scores []float64, members []bool, dir Direction) []ROCPoint {
+	return rocFromScores(scores, members, dir)
+}
+
+// rocFromScores 在原始分数上计算 ROC 曲线
+func rocFromScores(scores []float64, members []bool, dir Direction) []ROCPoint {
+	if len(scores) != len(members) {
+		panic("eval.ROC: 分数与成员真值长度不一致")
+	}
+
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return oriented(scores[order[a]], dir) > oriented(scores[order[b]], dir)
+	})
+
+	pos, neg := countClasses(members)
+	curve := []ROCPoint{{Threshold: oriented(math.Inf(1), dir), FPR: 0, TPR: 0}}
+
+	tp, fp := 0, 0
+	for i := 0; i < len(order); {
+		// 相同分数必须一起越过阈值
+		s := oriented(scores[order[i]], dir)
+		for i < len(order) && oriented(scores[order[i]], dir) == s {
+			if members[order[i]] {
+				tp++
+			} else {
+				fp++
+			}
+			i++
+		}
+		curve = append(curve, ROCPoint{
+			Threshold: oriented(s, dir),
+			FPR:       ratio(fp, neg),
+			TPR:       ratio(tp, pos),
+		})
+	}
+	return curve
+}
+
+// AUC 用梯形法计算 ROC 曲线下面积。
+// 对应 Python: sklearn.metrics.auc(fpr, tpr)
+// 等价于 Mann-Whitney U 统计量 (相同分数记 0.5)。
+func AUC(curve []ROCPoint) float64 {
+	area := 0.0
+	for i := 1; i < len(curve); i++ {
+		dx := curve[i].FPR - curve[i-1].FPR
+		area += dx * (curve[i].TPR + curve[i-1].TPR) / 2
+	}
+	return area
+}
+
+// TPRAtFPR 返回假阳性率不超过 maxFPR 时能达到的最大真阳性率。
+// 这是 LiRA (Carlini et al., 2022) 之后 MIA 论文的标准指标，例如 TPR@0.1%FPR。
+func TPRAtFPR(curve []ROCPoint, maxFPR float64) float64 {
+	best := 0.0
+	for _, p := range curve {
+		if p.FPR <= maxFPR && p.TPR > best {
+			best = p.TPR
+		}
+	}
+	return best
+}
+
+// BestBalancedAccuracy 返回最优阈值下的平衡准确率 (TPR + TNR) / 2 及对应阈值
+func BestBalancedAccuracy(curve []ROCPoint) (float64, float64) {
+	best := bestBalancedPoint(curve)
+	return (best.TPR + 1 - best.FPR) / 2, best.Threshold
+}
+
+// bestBalancedPoint 返回平衡准确率最高的 ROC 点
+func bestBalancedPoint(curve []ROCPoint) ROCPoint {
+	var best ROCPoint
+	bestAcc := -1.0
+	for _, p := range curve {
+		acc := (p.TPR + 1 - p.FPR) / 2
+		if acc > bestAcc {
+			bestAcc, best = acc, p
+		}
+	}
+	return best
+}
+
+func countClasses(members []bool) (pos, neg int) {
+	for _, m := range members {
+		if m {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	return pos, neg
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,288 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// Strategy 阈值校准策略
+type Strategy string
+
+const (
+	// StrategyMaxAccuracy 在带标签的校准集上取准确率最高的阈值
+	StrategyMaxAccuracy Strategy = "max_accuracy"
+	// StrategyFixedFPR 只用已知非成员，取假阳性率不超过目标值的最宽松阈值
+	StrategyFixedFPR Strategy = "fixed_fpr"
+	// StrategyShadow 用一个或多个影子模型上的攻击结果 (成员身份已知) 取准确率最高的阈值
+	StrategyShadow Strategy = "shadow"
+)
+
+// Threshold 校准好的成员判定阈值，可持久化后用于新的审计
+// 判定规则: 按 Direction 调整后的分数 >= Value 即判为成员。
+// 成员分数 (MembershipScore) 的两种校准方式:
+//   - 有带标签数据时 (max_accuracy / shadow): Platt 缩放 P(member) = sigmoid(PlattA*s + PlattB)
+//   - 只有非成员时 (fixed_fpr): 以非成员分数的高斯拟合为原假设，输出 Φ((s - NullMean) / NullStd)
+type Threshold struct {
+	Strategy        Strategy  `json:"strategy"`
+	Direction       Direction `json:"direction"`
+	Value           float64   `json:"threshold"`
+	TargetFPR       float64   `json:"target_fpr,omitempty"`
+	PlattA          float64   `json:"platt_a,omitempty"`
+	PlattB          float64   `json:"platt_b,omitempty"`
+	NullMean        float64   `json:"null_mean,omitempty"`
+	NullStd         float64   `json:"null_std,omitempty"`
+	CalibrationSize int       `json:"calibration_size"`
+}
+
+// ShadowSet 一个影子模型上的攻击结果及其成员真值
+type ShadowSet struct {
+	Results []core.AttackResult
+	Members []bool
+}
+
+// CalibrateMaxAccuracy 在带标签的校准集上取准确率最高的阈值
+func CalibrateMaxAccuracy(results []core.AttackResult, members []bool, dir Direction) (*Threshold, error) {
+	t, err := maxAccuracy(results, members, dir)
+	if err != nil {
+		return nil, err
+	}
+	t.Strategy = StrategyMaxAccuracy
+	return t, nil
+}
+
+// CalibrateShadow 汇总多个影子模型的攻击结果，取总体准确率最高的阈值
+func CalibrateShadow(shadows []ShadowSet, dir Direction) (*Threshold, error) {
+	var results []core.AttackResult
+	var members []bool
+	for i, s := range shadows {
+		if len(s.Results) != len(s.Members) {
+			return nil, fmt.Errorf("eval: 第 %d 个影子模型的结果与成员真值不对应", i)
+		}
+		results = append(results, s.Results...)
+		members = append(members, s.Members...)
+	}
+
+	t, err := maxAccuracy(results, members, dir)
+	if err != nil {
+		return nil, err
+	}
+	t.Strategy = StrategyShadow
+	return t, nil
+}
+
+// CalibrateFixedFPR 只用已知非成员的攻击结果，取假阳性率不超过 targetFPR 的最宽松阈值。
+// 按 HigherIsMember 判定时，攻击失败的非成员分数为 +Inf，任何阈值下都会被判为成员，
+// 它们计入允许误判的名额；仅它们就超过名额时返回错误。
+func CalibrateFixedFPR(nonMembers []core.AttackResult, targetFPR float64, dir Direction) (*Threshold, error) {
+	if len(nonMembers) == 0 {
+		return nil, fmt.Errorf("eval: 固定 FPR 校准需要至少一个非成员")
+	}
+	if targetFPR < 0 || targetFPR > 1 {
+		return nil, fmt.Errorf("eval: 目标 FPR %.4f 不在 [0, 1] 内", targetFPR)
+	}
+
+	// 调整方向后由大到小排序，允许前 k 个非成员被误判
+	scores := orientedScores(nonMembers, dir)
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+	k := int(math.Floor(targetFPR * float64(len(scores))))
+
+	value := math.Inf(-1)
+	if k < len(scores) {
+		if math.IsInf(scores[k], 1) {
+			failed := 0
+			for failed < len(scores) && math.IsInf(scores[failed], 1) {
+				failed++
+			}
+			return nil, fmt.Errorf("eval: %d 个非成员攻击失败，无论阈值多严都会被判为成员，超过目标 FPR %.4f 允许的 %d 个",
+				failed, targetFPR, k)
+		}
+		// 严格大于第 k+1 大的分数才判为成员
+		value = math.Nextafter(scores[k], math.Inf(1))
+	}
+
+	mean, std := mathutils.MeanStd(finiteScores(nonMembers, dir))
+	return &Threshold{
+		Strategy:        StrategyFixedFPR,
+		Direction:       dir,
+		Value:           clampInf(oriented(value, dir)),
+		TargetFPR:       targetFPR,
+		NullMean:        mean,
+		NullStd:         std,
+		CalibrationSize: len(scores),
+	}, nil
+}
+
+// IsMember 按阈值判定一条攻击结果是否为成员
+func (t *Threshold) IsMember(r core.AttackResult) bool {
+	return oriented(Score(r), t.Direction) >= oriented(t.Value, t.Direction)
+}
+
+// MembershipScore 返回校准后的成员分数 (0~1)
+func (t *Threshold) MembershipScore(r core.AttackResult) float64 {
+	s := oriented(Score(r), t.Direction)
+	if t.Strategy == StrategyFixedFPR {
+		if t.NullStd == 0 {
+			if s > t.NullMean {
+				return 1
+			}
+			return 0
+		}
+		return mathutils.NormalCDF((s - t.NullMean) / t.NullStd)
+	}
+	z := t.PlattA*s + t.PlattB
+	if math.IsNaN(z) { // PlattA 为 0 且攻击失败 (0 * Inf)
+		z = t.PlattB
+	}
+	return mathutils.Sigmoid(z)
+}
+
+// Apply 对每条结果填写 IsMember 与 MembershipScore，返回新切片
+func (t *Threshold) Apply(results []core.AttackResult) []core.AttackResult {
+	out := make([]core.AttackResult, len(results))
+	for i, r := range results {
+		r.IsMember = t.IsMember(r)
+		r.MembershipScore = t.MembershipScore(r)
+		out[i] = r
+	}
+	return out
+}
+
+// Save 把阈值保存为 JSON 文件
+func (t *Threshold) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadThreshold 从 JSON 文件读取阈值
+func LoadThreshold(path string) (*Threshold, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Threshold
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("eval: 解析阈值文件 %s 失败: %w", path, err)
+	}
+	return &t, nil
+}
+
+// maxAccuracy 在 ROC 曲线上找准确率 (非平衡) 最高的点，并拟合 Platt 缩放
+func maxAccuracy(results []core.AttackResult, members []bool, dir Direction) (*Threshold, error) {
+	if len(results) != len(members) {
+		return nil, fmt.Errorf("eval: %d 条结果与 %d 个成员真值不对应", len(results), len(members))
+	}
+	pos, neg := countClasses(members)
+	if pos == 0 || neg == 0 {
+		return nil, fmt.Errorf("eval: 校准集需要同时包含成员和非成员 (成员 %d, 非成员 %d)", pos, neg)
+	}
+
+	bestAcc, bestThr := -1.0, 0.0
+	for _, p := range ROC(results, members, dir) {
+		acc := (p.TPR*float64(pos) + (1-p.FPR)*float64(neg)) / float64(pos+neg)
+		if acc > bestAcc {
+			bestAcc, bestThr = acc, p.Threshold
+		}
+	}
+
+	a, b := fitPlatt(finiteScores(results, dir), members)
+	return &Threshold{
+		Direction:       dir,
+		Value:           clampInf(bestThr),
+		PlattA:          a,
+		PlattB:          b,
+		CalibrationSize: len(results),
+	}, nil
+}
+
+// finiteScores 返回按方向调整后的分数；±Inf (攻击失败) 被替换为有限分数中的极值，避免拟合时出现 NaN
+func finiteScores(results []core.AttackResult, dir Direction) []float64 {
+	scores := make([]float64, len(results))
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, r := range results {
+		scores[i] = oriented(Score(r), dir)
+		if !math.IsInf(scores[i], 0) {
+			lo = math.Min(lo, scores[i])
+			hi = math.Max(hi, scores[i])
+		}
+	}
+	if math.IsInf(lo, 0) {
+		lo, hi = 0, 0
+	}
+	for i, s := range scores {
+		if math.IsInf(s, 1) {
+			scores[i] = hi
+		} else if math.IsInf(s, -1) {
+			scores[i] = lo
+		}
+	}
+	return scores
+}
+
+// fitPlatt 用牛顿法拟合一维逻辑回归 P(y=1|x) = sigmoid(a*x + b)
+// 在标准化后的 x 上迭代，并加一个很小的 L2 正则，防止完全可分时系数发散。
+func fitPlatt(x []float64, y []bool) (float64, float64) {
+	mean, std := mathutils.MeanStd(x)
+	if std == 0 {
+		std = 1
+	}
+
+	const ridge = 1e-3
+	w, c := 0.0, 0.0
+	for iter := 0; iter < 100; iter++ {
+		// 梯度与 Hessian (2x2)
+		var gw, gc, hww, hwc, hcc float64
+		for i, xi := range x {
+			z := (xi - mean) / std
+			p := mathutils.Sigmoid(w*z + c)
+			t := 0.0
+			if y[i] {
+				t = 1
+			}
+			gw += (p - t) * z
+			gc += p - t
+			h := p * (1 - p)
+			hww += h * z * z
+			hwc += h * z
+			hcc += h
+		}
+		gw += ridge * w
+		hww += ridge
+		hcc += 1e-9
+
+		det := hww*hcc - hwc*hwc
+		if det == 0 {
+			break
+		}
+		dw := (hcc*gw - hwc*gc) / det
+		dc := (hww*gc - hwc*gw) / det
+		w -= dw
+		c -= dc
+		if math.Abs(dw) < 1e-10 && math.Abs(dc) < 1e-10 {
+			break
+		}
+	}
+
+	// 换算回原始尺度: w*(x-mean)/std + c
+	return w / std, c - w*mean/std
+}
+
+// clampInf 把 ±Inf 阈值换成 ±MaxFloat64，使其可以写入 JSON
+// (攻击失败的分数为 +Inf，仍然满足 >= MaxFloat64，判定结果不变)
+func clampInf(v float64) float64 {
+	if math.IsInf(v, 1) {
+		return math.MaxFloat64
+	}
+	if math.IsInf(v, -1) {
+		return -math.MaxFloat64
+	}
+	return v
+}
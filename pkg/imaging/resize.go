@@ -0,0 +1,43 @@
+package imaging
+
+import (
+	"math"
+)
+
+// Resize 对 CHW 展平向量做双线性缩放。
+// 对应 Python: F.interpolate(x, size=(dstH, dstW), mode="bilinear", align_corners=False)
+// 用途: 进化攻击在低维空间 (如 3x15x15) 采样扰动后，放大回 3x32x32。
+func Resize(img []float32, channels, srcH, srcW, dstH, dstW int) []float32 {
+	if len(img) != channels*srcH*srcW {
+		panic("imaging.Resize: 输入长度与 channels*srcH*srcW 不一致")
+	}
+
+	result := make([]float32, channels*dstH*dstW)
+	scaleY := float64(srcH) / float64(dstH)
+	scaleX := float64(srcW) / float64(dstW)
+
+	for c := 0; c < channels; c++ {
+		src := img[c*srcH*srcW : (c+1)*srcH*srcW]
+		dst := result[c*dstH*dstW : (c+1)*dstH*dstW]
+
+		for y := 0; y < dstH; y++ {
+			// align_corners=False 的坐标映射，并夹到边缘
+			sy := math.Max((float64(y)+0.5)*scaleY-0.5, 0)
+			y0 := int(sy)
+			y1 := min(y0+1, srcH-1)
+			fy := float32(sy - float64(y0))
+
+			for x := 0; x < dstW; x++ {
+				sx := math.Max((float64(x)+0.5)*scaleX-0.5, 0)
+				x0 := int(sx)
+				x1 := min(x0+1, srcW-1)
+				fx := float32(sx - float64(x0))
+
+				top := src[y0*srcW+x0]*(1-fx) + src[y0*srcW+x1]*fx
+				bottom := src[y1*srcW+x0]*(1-fx) + src[y1*srcW+x1]*fx
+				dst[y*dstW+x] = top*(1-fy) + bottom*fy
+			}
+		}
+	}
+	return result
+}
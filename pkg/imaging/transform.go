@@ -0,0 +1,144 @@
+package imaging
+
+import (
+	"math"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// ============================================================================
+// 图像语义变换工具库 (Image Transforms)
+// 对应 Python 库: torchvision.transforms.functional
+// 数据布局: CIFAR-10 二进制格式的 CHW 展平向量 (3 x 32 x 32 = 3072)，
+// 即先 1024 个 R，再 1024 个 G，最后 1024 个 B。
+// 几何变换统一使用双线性插值，越界区域填 0 (与 torchvision 默认一致)。
+// 所有函数都返回新切片，不修改输入。
+// ============================================================================
+
+// Rotate 绕图片中心逆时针旋转 degrees 度。
+// 对应 Python: TF.rotate(img, angle)
+func Rotate(img core.Image, degrees float64) core.Image {
+	rad := degrees * math.Pi / 180.0
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	// 输出像素 (x, y) 对应的源坐标 = 逆旋转
+	return warp(img, func(x, y float64) (float64, float64) {
+		return cos*x - sin*y, sin*x + cos*y
+	})
+}
+
+// Translate 平移图片，dx 为向右像素数，dy 为向下像素数 (可以是小数)。
+// 对应 Python: TF.affine(img, angle=0, translate=[dx, dy], scale=1, shear=0)
+func Translate(img core.Image, dx, dy float64) core.Image {
+	return warp(img, func(x, y float64) (float64, float64) {
+		return x - dx, y - dy
+	})
+}
+
+// Scale 以图片中心为原点缩放，factor > 1 放大，factor < 1 缩小。
+// 对应 Python: TF.affine(img, angle=0, translate=[0, 0], scale=factor, shear=0)
+func Scale(img core.Image, factor float64) core.Image {
+	if factor <= 0 {
+		panic("imaging.Scale: 缩放因子必须为正数")
+	}
+	return warp(img, func(x, y float64) (float64, float64) {
+		return x / factor, y / factor
+	})
+}
+
+// FlipHorizontal 左右翻转。
+// 对应 Python: TF.hflip(img)
+func FlipHorizontal(img core.Image) core.Image {
+	checkLayout(img)
+
+	result := make(core.Image, len(img))
+	plane := core.ImgHeight * core.ImgWidth
+	for c := 0; c < core.ImgChannels; c++ {
+		for y := 0; y < core.ImgHeight; y++ {
+			row := c*plane + y*core.ImgWidth
+			for x := 0; x < core.ImgWidth; x++ {
+				result[row+x] = img[row+core.ImgWidth-1-x]
+			}
+		}
+	}
+	return result
+}
+
+// AdjustBrightness 亮度调整：每个像素加上 delta。
+// 对应 Python: img + delta (调用方负责 Clip 回合法范围)
+func AdjustBrightness(img core.Image, delta float32) core.Image {
+	result := make(core.Image, len(img))
+	for i, v := range img {
+		result[i] = v + delta
+	}
+	return result
+}
+
+// AdjustContrast 对比度调整：每个通道围绕自身均值缩放 factor 倍。
+// 对应 Python: mean + (img - mean) * factor
+func AdjustContrast(img core.Image, factor float32) core.Image {
+	checkLayout(img)
+
+	result := make(core.Image, len(img))
+	plane := core.ImgHeight * core.ImgWidth
+	for c := 0; c < core.ImgChannels; c++ {
+		channel := img[c*plane : (c+1)*plane]
+
+		var sum float64
+		for _, v := range channel {
+			sum += float64(v)
+		}
+		mean := float32(sum / float64(plane))
+
+		for i, v := range channel {
+			result[c*plane+i] = mean + (v-mean)*factor
+		}
+	}
+	return result
+}
+
+// warp 通用几何变换：对每个输出像素 (以图片中心为原点的坐标)，
+// 通过 inverse 求出源坐标后做双线性采样。
+func warp(img core.Image, inverse func(x, y float64) (float64, float64)) core.Image {
+	checkLayout(img)
+
+	result := make(core.Image, len(img))
+	plane := core.ImgHeight * core.ImgWidth
+	cx := float64(core.ImgWidth-1) / 2.0
+	cy := float64(core.ImgHeight-1) / 2.0
+
+	for y := 0; y < core.ImgHeight; y++ {
+		for x := 0; x < core.ImgWidth; x++ {
+			sx, sy := inverse(float64(x)-cx, float64(y)-cy)
+			sx += cx
+			sy += cy
+			for c := 0; c < core.ImgChannels; c++ {
+				result[c*plane+y*core.ImgWidth+x] = bilinear(img[c*plane:(c+1)*plane], sx, sy)
+			}
+		}
+	}
+	return result
+}
+
+// bilinear 在单个通道上做双线性插值，越界的邻居按 0 计算。
+func bilinear(channel []float32, x, y float64) float32 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := float32(x-x0), float32(y-y0)
+	ix, iy := int(x0), int(y0)
+
+	at := func(px, py int) float32 {
+		if px < 0 || px >= core.ImgWidth || py < 0 || py >= core.ImgHeight {
+			return 0
+		}
+		return channel[py*core.ImgWidth+px]
+	}
+
+	top := at(ix, iy)*(1-fx) + at(ix+1, iy)*fx
+	bottom := at(ix, iy+1)*(1-fx) + at(ix+1, iy+1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+func checkLayout(img core.Image) {
+	if len(img) != core.FlattenedSize {
+		panic("imaging: 输入长度必须为 core.FlattenedSize (3x32x32)")
+	}
+}
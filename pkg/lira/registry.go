@@ -0,0 +1,86 @@
+package lira
+
+import (
+	"fmt"
+	"sync"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// ============================================================================
+// 纯标签 LiRA (Label-only Likelihood Ratio Attack)
+// 参考: Carlini et al., "Membership Inference Attacks From First Principles", 2022
+// 流程:
+//  1. Registry 登记若干本地参考模型，以及每个模型训练时用到了哪些样本
+//  2. Collect 用同一个攻击器在所有参考模型上攻击同一批样本，距离按 IN/OUT 存进 StatsStore
+//  3. Scorer 对每个样本拟合 IN/OUT 两个高斯，用似然比给目标模型上的距离打分
+// ============================================================================
+
+// SampleKey 返回样本在统计库中的唯一键。
+// 优先使用 Filename (LabelScan-Go 的 CifarLoader 会生成 "路径_#序号")，
+// 因为不同批次文件里的 ID 会重复。
+func SampleKey(s core.Sample) string {
+	if s.Filename != "" {
+		return s.Filename
+	}
+	return fmt.Sprintf("#%d", s.ID)
+}
+
+// RefModel 一个参考模型
+type RefModel struct {
+	Name    string
+	Model   core.Model
+	trainOn map[string]bool // 训练集中包含的样本键
+}
+
+// Trained 判断该参考模型训练时是否见过某个样本
+func (m RefModel) Trained(key string) bool {
+	return m.trainOn[key]
+}
+
+// Registry 参考模型登记表，可并发读写
+type Registry struct {
+	mu     sync.RWMutex
+	models []RefModel
+}
+
+// NewRegistry 创建空的参考模型登记表
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register 登记一个参考模型；trainedOn 是它训练集中样本的 SampleKey
+func (r *Registry) Register(name string, model core.Model, trainedOn []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.models {
+		if m.Name == name {
+			return fmt.Errorf("lira: 参考模型 %q 已登记", name)
+		}
+	}
+
+	set := make(map[string]bool, len(trainedOn))
+	for _, k := range trainedOn {
+		set[k] = true
+	}
+	r.models = append(r.models, RefModel{Name: name, Model: model, trainOn: set})
+	return nil
+}
+
+// Models 返回所有已登记的参考模型 (副本)
+func (r *Registry) Models() []RefModel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]RefModel, len(r.models))
+	copy(out, r.models)
+	return out
+}
+
+// Len 返回已登记的参考模型数量
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.models)
+}
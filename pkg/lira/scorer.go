@@ -0,0 +1,179 @@
+package lira
+
+import (
+	"math"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/mathutils"
+)
+
+// Scorer 用每个样本的 IN/OUT 高斯对目标模型上的距离做似然比打分
+type Scorer struct {
+	Store *StatsStore
+	// LogScale 在 log(距离) 上拟合高斯 (距离为正且右偏，取对数后更接近正态)
+	LogScale bool
+	// GlobalVariance 用所有样本汇总的方差代替逐样本方差。
+	// 参考模型很少 (每侧只有几个) 时逐样本方差很不稳定，LiRA 论文也推荐这样做。
+	GlobalVariance bool
+	// MinStd 标准差下限，防止只有一两个参考值时方差为 0 (默认 1e-3)
+	MinStd float64
+}
+
+// NewScorer 创建 LiRA 打分器
+func NewScorer(store *StatsStore) *Scorer {
+	return &Scorer{Store: store, MinStd: 1e-3}
+}
+
+// Score 返回样本为成员的后验概率 (IN/OUT 先验各半)。
+// 每一侧的参考结果是“攻击失败”与“距离服从高斯”的混合：失败率 p 用 (失败数 + 0.5) / (总数 + 1) 平滑估计。
+//   - 有 IN 数据 (距离或失败): 在线 LiRA，P = LR / (1 + LR)。
+//     目标攻击失败时 LR = p_in / p_out；成功时 LR = (1 - p_in) N(d; IN) / ((1 - p_out) N(d; OUT))，
+//     某一侧没有成功的距离时只用失败率。
+//   - 只有 OUT: 离线 LiRA，P = P_out(D < d) + 0.5 P_out(D = d)，即距离比非成员分布大多少；
+//     成功时为 (1 - p_out) Φ((d - μ_out) / σ_out)，失败时为 1 - p_out / 2 (失败视为比任何距离都远，p_out 不平滑)。
+//
+// distance 为 +Inf 表示目标模型上的攻击失败。
+// 第二个返回值为 false 表示该样本没有任何参考数据，无法打分。
+// GlobalVariance 模式下每次调用都要遍历整个统计库，批量打分请用 ScoreResults。
+func (sc *Scorer) Score(key string, distance float64) (float64, bool) {
+	return sc.score(key, distance, sc.pooled())
+}
+
+// pooledStd IN/OUT 的合并标准差
+type pooledStd struct {
+	in, out float64
+}
+
+// pooled GlobalVariance 模式下汇总整个统计库的合并标准差，否则返回 nil
+func (sc *Scorer) pooled() *pooledStd {
+	if !sc.GlobalVariance {
+		return nil
+	}
+	in, out := sc.globalStd()
+	return &pooledStd{in: in, out: out}
+}
+
+// score 与 Score 相同，pooled 不为 nil 时用它代替逐样本标准差
+func (sc *Scorer) score(key string, distance float64, pooled *pooledStd) (float64, bool) {
+	st, ok := sc.Store.Get(key)
+	if !ok || len(st.Out)+st.OutFailed == 0 {
+		return 0, false
+	}
+
+	d := sc.transform(distance)
+	failed := math.IsInf(d, 1)
+	inVals, outVals := sc.transformAll(st.In), sc.transformAll(st.Out)
+
+	outMean, outStd := mathutils.MeanStd(outVals)
+	inMean, inStd := mathutils.MeanStd(inVals)
+	if pooled != nil {
+		inStd, outStd = pooled.in, pooled.out
+	}
+	inStd = math.Max(inStd, sc.MinStd)
+	outStd = math.Max(outStd, sc.MinStd)
+
+	if len(inVals)+st.InFailed == 0 {
+		pOut := float64(st.OutFailed) / float64(len(outVals)+st.OutFailed)
+		if failed {
+			return 1 - pOut/2, true
+		}
+		if len(outVals) == 0 {
+			return 0, true
+		}
+		return (1 - pOut) * mathutils.NormalCDF((d-outMean)/outStd), true
+	}
+
+	// 在对数域计算似然比，避免下溢
+	pIn, pOut := failureRate(st.InFailed, len(inVals)), failureRate(st.OutFailed, len(outVals))
+	var logLR float64
+	if failed {
+		logLR = math.Log(pIn) - math.Log(pOut)
+	} else {
+		logLR = math.Log(1-pIn) - math.Log(1-pOut)
+		if len(inVals) > 0 && len(outVals) > 0 {
+			logLR += logNormalPDF(d, inMean, inStd) - logNormalPDF(d, outMean, outStd)
+		}
+	}
+	return 1 / (1 + math.Exp(-logLR)), true
+}
+
+// failureRate 平滑后的攻击失败率，没有参考数据时为 0.5
+func failureRate(failed, succeeded int) float64 {
+	return (float64(failed) + 0.5) / float64(failed+succeeded+1)
+}
+
+// ScoreResults 对目标模型上的攻击结果打分：
+// MembershipScore 填写后验概率，IsMember = 概率 > 0.5。
+// samples 必须与 results 一一对应；没有参考数据的样本保持原样。
+// GlobalVariance 模式下合并标准差只在开头计算一次。
+func (sc *Scorer) ScoreResults(results []core.AttackResult, samples []core.Sample) []core.AttackResult {
+	if len(results) != len(samples) {
+		panic("lira.ScoreResults: 结果与样本长度不一致")
+	}
+
+	pooled := sc.pooled()
+	out := make([]core.AttackResult, len(results))
+	for i, r := range results {
+		dist := r.Distance
+		if !r.IsSuccess {
+			dist = math.Inf(1)
+		}
+		if p, ok := sc.score(SampleKey(samples[i]), dist, pooled); ok {
+			r.MembershipScore = p
+			r.IsMember = p > 0.5
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// globalStd 汇总所有样本的组内离差，返回 IN/OUT 的合并标准差
+func (sc *Scorer) globalStd() (float64, float64) {
+	var inSq, outSq float64
+	var inN, outN int
+	for _, key := range sc.Store.Keys() {
+		st, _ := sc.Store.Get(key)
+		sq, n := sumSquares(sc.transformAll(st.In))
+		inSq, inN = inSq+sq, inN+n
+		sq, n = sumSquares(sc.transformAll(st.Out))
+		outSq, outN = outSq+sq, outN+n
+	}
+
+	std := func(sq float64, n int) float64 {
+		if n == 0 {
+			return 0
+		}
+		return math.Sqrt(sq / float64(n))
+	}
+	return std(inSq, inN), std(outSq, outN)
+}
+
+// sumSquares 返回组内离差平方和与样本数
+func sumSquares(vals []float64) (float64, int) {
+	mean, _ := mathutils.MeanStd(vals)
+	var sq float64
+	for _, v := range vals {
+		sq += (v - mean) * (v - mean)
+	}
+	return sq, len(vals)
+}
+
+func (sc *Scorer) transform(d float64) float64 {
+	if sc.LogScale && !math.IsInf(d, 1) {
+		return math.Log(math.Max(d, 1e-12))
+	}
+	return d
+}
+
+func (sc *Scorer) transformAll(vals []float64) []float64 {
+	out := make([]float64, len(vals))
+	for i, v := range vals {
+		out[i] = sc.transform(v)
+	}
+	return out
+}
+
+func logNormalPDF(x, mean, std float64) float64 {
+	z := (x - mean) / std
+	return -0.5*z*z - math.Log(std) - 0.5*math.Log(2*math.Pi)
+}
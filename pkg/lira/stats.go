@@ -0,0 +1,161 @@
+package lira
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// SampleStats 单个样本在参考模型上的边界距离
+type SampleStats struct {
+	In  []float64 `json:"in"`  // 训练时见过该样本的参考模型上的距离
+	Out []float64 `json:"out"` // 没见过该样本的参考模型上的距离
+
+	// 攻击失败 (找不到对抗样本) 的次数。失败没有距离，不参与高斯拟合，
+	// 但鲁棒的 IN 样本恰恰最容易失败，丢掉会让 IN 分布偏低；打分时按失败率计入似然。
+	InFailed  int `json:"in_failed,omitempty"`
+	OutFailed int `json:"out_failed,omitempty"`
+}
+
+// StatsStore 按样本键保存 IN/OUT 距离，可并发读写并持久化为 JSON
+type StatsStore struct {
+	mu    sync.RWMutex
+	stats map[string]*SampleStats
+}
+
+// NewStatsStore 创建空的统计库
+func NewStatsStore() *StatsStore {
+	return &StatsStore{stats: make(map[string]*SampleStats)}
+}
+
+// Add 记录一次参考模型上的攻击距离
+func (s *StatsStore) Add(key string, distance float64, in bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.stats[key]
+	if !ok {
+		st = &SampleStats{}
+		s.stats[key] = st
+	}
+	if in {
+		st.In = append(st.In, distance)
+	} else {
+		st.Out = append(st.Out, distance)
+	}
+}
+
+// AddFailure 记录一次参考模型上的攻击失败
+func (s *StatsStore) AddFailure(key string, in bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.stats[key]
+	if !ok {
+		st = &SampleStats{}
+		s.stats[key] = st
+	}
+	if in {
+		st.InFailed++
+	} else {
+		st.OutFailed++
+	}
+}
+
+// Get 返回某个样本的统计 (副本)
+func (s *StatsStore) Get(key string) (SampleStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, ok := s.stats[key]
+	if !ok {
+		return SampleStats{}, false
+	}
+	return SampleStats{
+		In:        append([]float64(nil), st.In...),
+		Out:       append([]float64(nil), st.Out...),
+		InFailed:  st.InFailed,
+		OutFailed: st.OutFailed,
+	}, true
+}
+
+// Keys 返回统计库中所有样本键
+func (s *StatsStore) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.stats))
+	for k := range s.stats {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Save 把统计库保存为 JSON 文件
+func (s *StatsStore) Save(path string) error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.stats, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadStatsStore 从 JSON 文件读取统计库
+func LoadStatsStore(path string) (*StatsStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := NewStatsStore()
+	if err := json.Unmarshal(data, &s.stats); err != nil {
+		return nil, fmt.Errorf("lira: 解析统计库 %s 失败: %w", path, err)
+	}
+	return s, nil
+}
+
+// Collect 用 attacker 在所有参考模型上攻击 samples，把距离按 IN/OUT 写入 store。
+// 攻击失败 (找不到对抗样本) 记为失败次数 (见 SampleStats)，与 Scorer 对目标模型上失败的处理一致。
+// workers 为并发攻击的 goroutine 数 (参考 worker.Auditor)。
+func Collect(attacker core.Attacker, registry *Registry, samples []core.Sample, store *StatsStore, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	type job struct {
+		sample core.Sample
+		ref    RefModel
+	}
+
+	models := registry.Models()
+	jobs := make(chan job, len(samples)*len(models))
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res := attacker.Attack(j.sample, j.ref.Model)
+				key := SampleKey(j.sample)
+				if !res.IsSuccess {
+					store.AddFailure(key, j.ref.Trained(key))
+					continue
+				}
+				store.Add(key, res.Distance, j.ref.Trained(key))
+			}
+		}()
+	}
+
+	for _, s := range samples {
+		for _, m := range models {
+			jobs <- job{sample: s, ref: m}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
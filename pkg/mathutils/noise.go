@@ -87,3 +87,15 @@ func GenUniform(size int, min, max float64) []float32 {
 	}
 	return result
 }
+
+// RandPerm 生成 [0, n) 的随机排列。
+// 对应 Python: np.random.permutation(n)
+//
+// 用途:
+//   - 稀疏攻击 (Pointwise) 中随机打乱待恢复像素的顺序，避免总是偏向图片左上角。
+func RandPerm(n int) []int {
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+
+	return rng.Perm(n)
+}
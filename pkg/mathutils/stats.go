@@ -99,3 +99,42 @@ func Softmax(logits []float32) []float32 {
 
 	return probs
 }
+
+// Mean 计算 float64 切片的均值，空切片返回 0。
+// 对应 Python: np.mean(x)
+func Mean(x []float64) float64 {
+	m, _ := MeanStd(x)
+	return m
+}
+
+// MeanStd 计算均值与总体标准差 (除以 n)，空切片返回 (0, 0)。
+// 对应 Python: np.mean(x), np.std(x)
+// 用途: 阈值校准、LiRA 的高斯拟合等需要一维分数分布统计量的地方。
+func MeanStd(x []float64) (float64, float64) {
+	if len(x) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	mean := sum / float64(len(x))
+
+	var sq float64
+	for _, v := range x {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(x)))
+}
+
+// Sigmoid 逻辑函数 1 / (1 + e^-z)。
+// 对应 Python: scipy.special.expit
+func Sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// NormalCDF 标准正态分布的累积分布函数 Φ(z)。
+// 对应 Python: scipy.stats.norm.cdf
+func NormalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// ============================================================================
+// 纯 Go 参考目标模型 (Reference Target Models)
+// 用于端到端测试：cmd/attack_test 的 SimpleModel 与 LabelScan-Go 的 MockModel
+// 都不会记住训练样本，审计它们永远得不到泄露。这里的模型都在给定样本上训练，
+// 同一种子得到完全相同的参数，因此 CI 可以跑一次完整审计并检查 AUC > 0.5。
+//
+// 训练样本的格式与 LabelScan-Go 的 dataset.CifarLoader 一致：Data 为 CHW、[0, 1] 的 float32，Label 为类别；
+// 本模块中用 dataset.LoadCifarBatch 读取 CIFAR-10 二进制文件即可直接训练。
+// ============================================================================
+
+// checkSamples 校验训练样本并返回输入维度与类别数 (最大标签 + 1，且不少于 numClasses)
+func checkSamples(samples []core.Sample, numClasses int) (dim, classes int, err error) {
+	if len(samples) == 0 {
+		return 0, 0, fmt.Errorf("models: 训练集为空")
+	}
+	dim = len(samples[0].Data)
+	classes = numClasses
+	for i, s := range samples {
+		if len(s.Data) != dim {
+			return 0, 0, fmt.Errorf("models: 第 %d 个样本长度 %d，与第一个样本 (%d) 不一致", i, len(s.Data), dim)
+		}
+		if s.Label < 0 {
+			return 0, 0, fmt.Errorf("models: 第 %d 个样本标签 %d 为负数", i, s.Label)
+		}
+		classes = max(classes, s.Label+1)
+	}
+	return dim, classes, nil
+}
+
+func checkInput(img core.Image, dim int) error {
+	if dim == 0 {
+		return fmt.Errorf("models: 模型尚未训练")
+	}
+	if len(img) != dim {
+		return fmt.Errorf("models: 图片长度 %d，模型需要 %d", len(img), dim)
+	}
+	return nil
+}
+
+// predictBatch 逐张调用 predict
+func predictBatch(imgs []core.Image, predict func(core.Image) (int, error)) ([]int, error) {
+	labels := make([]int, len(imgs))
+	for i, img := range imgs {
+		l, err := predict(img)
+		if err != nil {
+			return nil, err
+		}
+		labels[i] = l
+	}
+	return labels, nil
+}
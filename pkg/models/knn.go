@@ -0,0 +1,98 @@
+package models
+
+import (
+	"sort"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// KNNConfig k 近邻参数
+type KNNConfig struct {
+	K int // 近邻数 (不大于 0 时取默认 1：纯记忆模型，训练样本一定被分对，成员泄露最明显)
+}
+
+// KNN k 近邻分类器 (L2 距离)，实现 core.Model。
+// 它把训练集原样存下来，是“记忆”的极端情形，用来验证审计流程一定能发现泄露。
+type KNN struct {
+	config KNNConfig
+	data   []core.Image
+	labels []int
+	dim    int
+}
+
+// NewKNN 创建 k 近邻分类器
+func NewKNN(cfg KNNConfig) *KNN {
+	if cfg.K <= 0 {
+		cfg.K = 1
+	}
+	return &KNN{config: cfg}
+}
+
+// Train 记住训练样本
+func (m *KNN) Train(samples []core.Sample) error {
+	dim, _, err := checkSamples(samples, 0)
+	if err != nil {
+		return err
+	}
+	m.dim = dim
+	m.data = make([]core.Image, len(samples))
+	m.labels = make([]int, len(samples))
+	for i, s := range samples {
+		m.data[i] = append(core.Image(nil), s.Data...)
+		m.labels[i] = s.Label
+	}
+	return nil
+}
+
+// Predict 实现 core.Model 接口：k 个近邻投票，票数相同时取离得最近的那一类
+func (m *KNN) Predict(img core.Image) (int, error) {
+	if err := checkInput(img, m.dim); err != nil {
+		return 0, err
+	}
+
+	type neighbor struct {
+		dist  float32
+		label int
+	}
+	// 插入排序维护最近的 k 个 (k 很小，比整体排序快得多)
+	k := min(m.config.K, len(m.data))
+	nearest := make([]neighbor, 0, k+1)
+	for i, x := range m.data {
+		var d float32
+		for j, v := range x {
+			diff := v - img[j]
+			d += diff * diff
+		}
+		if len(nearest) == k && d >= nearest[k-1].dist {
+			continue
+		}
+		pos := sort.Search(len(nearest), func(p int) bool { return nearest[p].dist > d })
+		nearest = append(nearest, neighbor{})
+		copy(nearest[pos+1:], nearest[pos:])
+		nearest[pos] = neighbor{d, m.labels[i]}
+		if len(nearest) > k {
+			nearest = nearest[:k]
+		}
+	}
+
+	votes := make(map[int]int)
+	best, bestVotes := nearest[0].label, 0
+	for _, n := range nearest {
+		votes[n.label]++
+		// 按距离顺序遍历，只有票数严格更多才替换，因此平票时保留更近的类别
+		if votes[n.label] > bestVotes {
+			best, bestVotes = n.label, votes[n.label]
+		}
+	}
+	return best, nil
+}
+
+// PredictBatch 实现 core.Model 接口
+func (m *KNN) PredictBatch(imgs []core.Image) ([]int, error) {
+	return predictBatch(imgs, m.Predict)
+}
+
+// GetInputSize 实现 core.Model 接口
+func (m *KNN) GetInputSize() int {
+	return m.dim
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// MLPConfig 单隐层感知机训练参数
+type MLPConfig struct {
+	Hidden       int     // 隐层宽度 (默认 64)
+	NumClasses   int     // 类别数下限 (默认 10)
+	Epochs       int     // 训练轮数 (默认 50；小数据上训练久一些更容易过拟合，泄露更明显)
+	LearningRate float64 // SGD 学习率 (默认 0.01)
+	L2           float64 // 权重衰减 (默认 0，不正则)
+	Seed         int64   // 初始化与打乱顺序的随机种子
+}
+
+// MLP 单隐层 ReLU 网络，实现 core.Model
+type MLP struct {
+	config MLPConfig
+	w1     [][]float64 // [隐层][像素]
+	b1     []float64
+	w2     [][]float64 // [类别][隐层]
+	b2     []float64
+	dim    int
+}
+
+// NewMLP 创建单隐层感知机
+func NewMLP(cfg MLPConfig) *MLP {
+	if cfg.Hidden == 0 {
+		cfg.Hidden = 64
+	}
+	if cfg.NumClasses == 0 {
+		cfg.NumClasses = 10
+	}
+	if cfg.Epochs == 0 {
+		cfg.Epochs = 50
+	}
+	if cfg.LearningRate == 0 {
+		cfg.LearningRate = 0.01
+	}
+	return &MLP{config: cfg}
+}
+
+// Train He 初始化后用逐样本 SGD 最小化交叉熵
+func (m *MLP) Train(samples []core.Sample) error {
+	dim, classes, err := checkSamples(samples, m.config.NumClasses)
+	if err != nil {
+		return err
+	}
+	rng := rand.New(rand.NewSource(m.config.Seed))
+	m.dim = dim
+	m.w1, m.b1 = heInit(rng, m.config.Hidden, dim), make([]float64, m.config.Hidden)
+	m.w2, m.b2 = heInit(rng, classes, m.config.Hidden), make([]float64, classes)
+
+	lr, decay := m.config.LearningRate, 1-m.config.LearningRate*m.config.L2
+	gradHidden := make([]float64, m.config.Hidden)
+	for epoch := 0; epoch < m.config.Epochs; epoch++ {
+		for _, idx := range rng.Perm(len(samples)) {
+			s := samples[idx]
+			hidden, logits := m.forward(s.Data)
+			probs := softmax(logits)
+
+			// 输出层
+			for i := range gradHidden {
+				gradHidden[i] = 0
+			}
+			for c, w := range m.w2 {
+				g := probs[c]
+				if c == s.Label {
+					g--
+				}
+				for i, h := range hidden {
+					gradHidden[i] += g * w[i]
+					w[i] = w[i]*decay - lr*g*h
+				}
+				m.b2[c] -= lr * g
+			}
+
+			// 隐层 (ReLU 导数：激活为 0 的单元不回传)
+			for i, w := range m.w1 {
+				if hidden[i] <= 0 {
+					continue
+				}
+				g := gradHidden[i]
+				for j, x := range s.Data {
+					w[j] = w[j]*decay - lr*g*center(x)
+				}
+				m.b1[i] -= lr * g
+			}
+		}
+	}
+	return nil
+}
+
+// Predict 实现 core.Model 接口
+func (m *MLP) Predict(img core.Image) (int, error) {
+	if err := checkInput(img, m.dim); err != nil {
+		return 0, err
+	}
+	_, logits := m.forward(img)
+	return argmax(logits), nil
+}
+
+// PredictBatch 实现 core.Model 接口
+func (m *MLP) PredictBatch(imgs []core.Image) ([]int, error) {
+	return predictBatch(imgs, m.Predict)
+}
+
+// GetInputSize 实现 core.Model 接口
+func (m *MLP) GetInputSize() int {
+	return m.dim
+}
+
+// forward 返回隐层激活与输出 logits
+func (m *MLP) forward(x core.Image) ([]float64, []float64) {
+	hidden := make([]float64, len(m.w1))
+	for i, w := range m.w1 {
+		z := m.b1[i]
+		for j, v := range x {
+			z += w[j] * center(v)
+		}
+		hidden[i] = math.Max(z, 0)
+	}
+
+	logits := make([]float64, len(m.w2))
+	for c, w := range m.w2 {
+		z := m.b2[c]
+		for i, h := range hidden {
+			z += w[i] * h
+		}
+		logits[c] = z
+	}
+	return hidden, logits
+}
+
+// center 把 [0, 1] 的像素平移到 [-0.5, 0.5]，让第一层的输入零均值，SGD 更稳定
+func center(v float32) float64 {
+	return float64(v) - 0.5
+}
+
+// heInit He 正态初始化：N(0, 2 / fanIn)
+func heInit(rng *rand.Rand, rows, fanIn int) [][]float64 {
+	std := math.Sqrt(2 / float64(fanIn))
+	w := make([][]float64, rows)
+	for i := range w {
+		w[i] = make([]float64, fanIn)
+		for j := range w[i] {
+			w[i][j] = rng.NormFloat64() * std
+		}
+	}
+	return w
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// SoftmaxConfig 多类逻辑回归 (softmax 回归) 训练参数
+type SoftmaxConfig struct {
+	NumClasses   int     // 类别数下限 (默认 10；训练集中出现更大的标签时自动扩大)
+	Epochs       int     // 训练轮数 (默认 30)
+	LearningRate float64 // SGD 学习率 (默认 0.01)
+	L2           float64 // 权重衰减 (默认 1e-4)
+	Seed         int64   // 打乱顺序的随机种子
+}
+
+// Softmax 多类逻辑回归，实现 core.Model。线性模型记忆能力有限，泄露通常比 KNN 弱。
+type Softmax struct {
+	config  SoftmaxConfig
+	weights [][]float64 // [类别][像素]
+	bias    []float64
+	dim     int
+}
+
+// NewSoftmax 创建 softmax 回归模型
+func NewSoftmax(cfg SoftmaxConfig) *Softmax {
+	if cfg.NumClasses == 0 {
+		cfg.NumClasses = 10
+	}
+	if cfg.Epochs == 0 {
+		cfg.Epochs = 30
+	}
+	if cfg.LearningRate == 0 {
+		cfg.LearningRate = 0.01
+	}
+	if cfg.L2 == 0 {
+		cfg.L2 = 1e-4
+	}
+	return &Softmax{config: cfg}
+}
+
+// Train 用逐样本 SGD 最小化交叉熵
+func (m *Softmax) Train(samples []core.Sample) error {
+	dim, classes, err := checkSamples(samples, m.config.NumClasses)
+	if err != nil {
+		return err
+	}
+	m.dim = dim
+	m.weights = make([][]float64, classes)
+	for c := range m.weights {
+		m.weights[c] = make([]float64, dim)
+	}
+	m.bias = make([]float64, classes)
+
+	rng := rand.New(rand.NewSource(m.config.Seed))
+	lr, decay := m.config.LearningRate, 1-m.config.LearningRate*m.config.L2
+	for epoch := 0; epoch < m.config.Epochs; epoch++ {
+		for _, idx := range rng.Perm(len(samples)) {
+			s := samples[idx]
+			probs := softmax(m.logits(s.Data))
+			for c, w := range m.weights {
+				g := probs[c]
+				if c == s.Label {
+					g--
+				}
+				for j, x := range s.Data {
+					w[j] = w[j]*decay - lr*g*float64(x)
+				}
+				m.bias[c] -= lr * g
+			}
+		}
+	}
+	return nil
+}
+
+// Predict 实现 core.Model 接口
+func (m *Softmax) Predict(img core.Image) (int, error) {
+	if err := checkInput(img, m.dim); err != nil {
+		return 0, err
+	}
+	return argmax(m.logits(img)), nil
+}
+
+// PredictBatch 实现 core.Model 接口
+func (m *Softmax) PredictBatch(imgs []core.Image) ([]int, error) {
+	return predictBatch(imgs, m.Predict)
+}
+
+// GetInputSize 实现 core.Model 接口
+func (m *Softmax) GetInputSize() int {
+	return m.dim
+}
+
+func (m *Softmax) logits(x core.Image) []float64 {
+	out := make([]float64, len(m.weights))
+	for c, w := range m.weights {
+		z := m.bias[c]
+		for j, v := range x {
+			z += w[j] * float64(v)
+		}
+		out[c] = z
+	}
+	return out
+}
+
+// softmax 数值稳定的 softmax (先减去最大值)
+func softmax(z []float64) []float64 {
+	hi := z[argmax(z)]
+	out := make([]float64, len(z))
+	var sum float64
+	for i, v := range z {
+		out[i] = math.Exp(v - hi)
+		sum += out[i]
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
+}
+
+func argmax(z []float64) int {
+	best := 0
+	for i, v := range z {
+		if v > z[best] {
+			best = i
+		}
+	}
+	return best
+}
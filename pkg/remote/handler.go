@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// HandlerConfig HTTP 服务端参数
+type HandlerConfig struct {
+	AuthHeader string // 认证头名称 (默认 Authorization)
+	AuthToken  string // 要求的认证头取值 (为空则不校验)
+	MaxBatch   int    // 单次批量请求的最大图片数 (默认 1024)
+}
+
+// NewHandler 把任意本地 core.Model 包装成 HTTPModel 所用协议的 HTTP 服务。
+// 既可以用 httptest.NewServer 起一个本地替身做测试或当作模拟攻击目标，
+// 也可以用 http.ListenAndServe 把 Go 模型真正部署出去。
+func NewHandler(model core.Model, cfg HandlerConfig) http.Handler {
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "Authorization"
+	}
+	if cfg.MaxBatch == 0 {
+		cfg.MaxBatch = 1024
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /predict", func(w http.ResponseWriter, r *http.Request) {
+		var req PredictRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, PredictResponse{Error: "请求体不是合法 JSON: " + err.Error()})
+			return
+		}
+		if err := checkSize(req.Pixels, model.GetInputSize()); err != nil {
+			writeJSON(w, http.StatusBadRequest, PredictResponse{Error: err.Error()})
+			return
+		}
+		label, err := model.Predict(req.Pixels)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, PredictResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, PredictResponse{Label: label})
+	})
+	mux.HandleFunc("POST /predict_batch", func(w http.ResponseWriter, r *http.Request) {
+		var req BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, BatchResponse{Error: "请求体不是合法 JSON: " + err.Error()})
+			return
+		}
+		if len(req.Images) > cfg.MaxBatch {
+			writeJSON(w, http.StatusRequestEntityTooLarge, BatchResponse{Error: fmt.Sprintf("批量大小 %d 超过上限 %d", len(req.Images), cfg.MaxBatch)})
+			return
+		}
+		for _, img := range req.Images {
+			if err := checkSize(img, model.GetInputSize()); err != nil {
+				writeJSON(w, http.StatusBadRequest, BatchResponse{Error: err.Error()})
+				return
+			}
+		}
+		labels, err := model.PredictBatch(req.Images)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, BatchResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, BatchResponse{Labels: labels})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.AuthToken != "" && r.Header.Get(cfg.AuthHeader) != cfg.AuthToken {
+			writeJSON(w, http.StatusUnauthorized, PredictResponse{Error: "认证失败"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func checkSize(img core.Image, want int) error {
+	if len(img) != want {
+		return fmt.Errorf("图片长度 %d，模型需要 %d", len(img), want)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,230 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// ============================================================================
+// HTTP JSON 模型客户端
+//
+// 协议 (服务端可用任意语言实现，参考实现见 NewHandler):
+//
+//	POST {Endpoint}/predict        {"pixels": [f32 x 3072]}          -> {"label": 3}
+//	POST {Endpoint}/predict_batch  {"images": [[f32 x 3072], ...]}   -> {"labels": [3, 7, ...]}
+//
+// 像素为 CHW 顺序、取值 [0, 1]；出错时返回非 2xx 状态码，响应体可带 {"error": "..."}。
+// ============================================================================
+
+// PredictRequest 单张预测请求
+type PredictRequest struct {
+	Pixels core.Image `json:"pixels"`
+}
+
+// PredictResponse 单张预测响应
+type PredictResponse struct {
+	Label int    `json:"label"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchRequest 批量预测请求
+type BatchRequest struct {
+	Images []core.Image `json:"images"`
+}
+
+// BatchResponse 批量预测响应
+type BatchResponse struct {
+	Labels []int  `json:"labels"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HTTPConfig HTTP 客户端参数
+type HTTPConfig struct {
+	Endpoint     string        // 服务根地址，例如 http://127.0.0.1:8000
+	Timeout      time.Duration // 单次请求超时 (默认 10s)
+	MaxRetries   int           // 网络错误、5xx、429 时的最大重试次数 (默认 3，负数表示不重试)
+	RetryBackoff time.Duration // 重试退避基数，第 n 次重试在 [0, RetryBackoff * 2^min(n, 10)) 内随机等待 (默认 100ms，负数表示立即重试)
+	MaxIdleConns int           // 连接池中保留的空闲连接数 (默认 64)
+	AuthHeader   string        // 认证头名称 (默认 Authorization)
+	AuthToken    string        // 认证头的值，例如 "Bearer xxx" (为空则不发送)
+	InputSize    int           // GetInputSize 的返回值 (默认 core.FlattenedSize)
+}
+
+// HTTPModel 通过 HTTP JSON 接口访问远程模型，实现 core.Model，可被多个 goroutine 共享
+type HTTPModel struct {
+	config HTTPConfig
+	client *http.Client
+}
+
+// NewHTTPModel 创建 HTTP 模型客户端
+func NewHTTPModel(cfg HTTPConfig) *HTTPModel {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxIdleConns == 0 {
+		cfg.MaxIdleConns = 64
+	}
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "Authorization"
+	}
+	if cfg.InputSize == 0 {
+		cfg.InputSize = core.FlattenedSize
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConns, // 只连一个服务，默认的每主机 2 个空闲连接远远不够
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &HTTPModel{
+		config: cfg,
+		client: &http.Client{Transport: transport, Timeout: cfg.Timeout},
+	}
+}
+
+// Predict 实现 core.Model 接口
+func (m *HTTPModel) Predict(img core.Image) (int, error) {
+	var resp PredictResponse
+	if err := m.post("/predict", PredictRequest{Pixels: img}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Label, nil
+}
+
+// PredictBatch 实现 core.Model 接口
+func (m *HTTPModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	var resp BatchResponse
+	if err := m.post("/predict_batch", BatchRequest{Images: imgs}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Labels) != len(imgs) {
+		return nil, fmt.Errorf("remote: 批量请求 %d 张图，服务端返回 %d 个标签", len(imgs), len(resp.Labels))
+	}
+	return resp.Labels, nil
+}
+
+// GetInputSize 实现 core.Model 接口
+func (m *HTTPModel) GetInputSize() int {
+	return m.config.InputSize
+}
+
+// statusError 服务端返回的非 2xx 状态
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("remote: 服务端返回 %d: %s", e.code, e.msg)
+}
+
+// transportError 请求没能完整往返 (连接失败、超时、读响应体中断)
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("remote: 请求失败: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryable 只有网络错误、5xx 与 429 值得重试。
+// 其余 4xx 以及 200 响应解析失败 (协议不匹配) 重试也不会变。
+func retryable(err error) bool {
+	switch e := err.(type) {
+	case *transportError:
+		return true
+	case *statusError:
+		return e.code >= 500 || e.code == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// post 发送 JSON 请求并解析响应，失败时按指数退避 + 随机抖动重试
+func (m *HTTPModel) post(path string, req, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = m.do(path, body, resp)
+		if err == nil || !retryable(err) || attempt >= m.config.MaxRetries {
+			return err
+		}
+		time.Sleep(jitter(m.config.RetryBackoff, attempt))
+	}
+}
+
+// maxBackoffShift 退避时间最多翻倍的次数，MaxRetries 很大时避免移位溢出
+const maxBackoffShift = 10
+
+// jitter 第 attempt 次重试前的等待时间。
+// Full jitter：多个并发攻击 worker 同时失败时不会在同一时刻一起重试。
+func jitter(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	backoff := base << min(attempt, maxBackoffShift)
+	if backoff <= 0 { // base 本身极大时移位仍可能溢出
+		backoff = base
+	}
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
+
+func (m *HTTPModel) do(path string, body []byte, resp any) error {
+	req, err := http.NewRequest(http.MethodPost, m.config.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.config.AuthToken != "" {
+		req.Header.Set(m.config.AuthHeader, m.config.AuthToken)
+	}
+
+	r, err := m.client.Do(req)
+	if err != nil {
+		return &transportError{err}
+	}
+	defer r.Body.Close()
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &transportError{err}
+	}
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+		return &statusError{code: r.StatusCode, msg: msg}
+	}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("remote: 解析响应失败: %w", err)
+	}
+	return nil
+}
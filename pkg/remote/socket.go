@@ -0,0 +1,328 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/dataset"
+)
+
+// ============================================================================
+// 二进制帧套接字协议 (TCP / Unix)
+// HSJA 动辄上百万次查询，每张图 3072 个 float32 用 JSON 传输太浪费，这里直接传原始字节。
+// 所有字段均为小端序，每个帧是 16 字节头部 + 负载:
+//
+//	请求头: magic uint16 | version uint8 | encoding uint8 | id uint32 | n uint32 | d uint32
+//	        负载 n*d 个 float32 (EncodingFloat32) 或 n*d 个 uint8 (EncodingUint8，像素 * 255)
+//	响应头: magic uint16 | version uint8 | status uint8   | id uint32 | n uint32 | 保留 uint32
+//	        status == 0: 负载 n 个 int32 标签；否则负载为 n 字节 UTF-8 错误信息
+//
+// id 由客户端分配，服务端原样带回。一个连接上可以同时有多个请求在途 (pipelining)，
+// 服务端可以乱序返回，客户端按 id 把响应交给对应的调用方。
+// ============================================================================
+
+const (
+	frameMagic   = 0x4D49 // "MI"
+	frameVersion = 1
+	headerSize   = 16
+
+	// maxFramePayload 单帧负载上限，防止损坏的头部导致超大内存分配
+	maxFramePayload = 1 << 30
+)
+
+// Encoding 像素的传输编码
+type Encoding uint8
+
+const (
+	// EncodingFloat32 原始 float32，无损
+	EncodingFloat32 Encoding = 1
+	// EncodingUint8 量化到 0~255，体积只有 1/4；
+	// 只适合本身就是 8 位图片的模型，HSJA 的细小扰动会在量化中丢失
+	EncodingUint8 Encoding = 2
+)
+
+type frameHeader struct {
+	flag uint8 // 请求中为 encoding，响应中为 status
+	id   uint32
+	n    uint32
+	d    uint32
+}
+
+func writeHeader(w io.Writer, h frameHeader) error {
+	var buf [headerSize]byte
+	binary.LittleEndian.PutUint16(buf[0:], frameMagic)
+	buf[2] = frameVersion
+	buf[3] = h.flag
+	binary.LittleEndian.PutUint32(buf[4:], h.id)
+	binary.LittleEndian.PutUint32(buf[8:], h.n)
+	binary.LittleEndian.PutUint32(buf[12:], h.d)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+func readHeader(r io.Reader) (frameHeader, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return frameHeader{}, err
+	}
+	if binary.LittleEndian.Uint16(buf[0:]) != frameMagic || buf[2] != frameVersion {
+		return frameHeader{}, fmt.Errorf("remote: 帧头不合法 (magic %#x, version %d)", binary.LittleEndian.Uint16(buf[0:]), buf[2])
+	}
+	return frameHeader{
+		flag: buf[3],
+		id:   binary.LittleEndian.Uint32(buf[4:]),
+		n:    binary.LittleEndian.Uint32(buf[8:]),
+		d:    binary.LittleEndian.Uint32(buf[12:]),
+	}, nil
+}
+
+// SocketConfig 套接字客户端参数
+type SocketConfig struct {
+	Network     string        // "tcp" 或 "unix" (默认 tcp)
+	Address     string        // 例如 127.0.0.1:9000 或 /tmp/model.sock
+	Encoding    Encoding      // 像素编码 (默认 EncodingFloat32)
+	InputSize   int           // 每张图的像素数 (默认 core.FlattenedSize)
+	DialTimeout time.Duration // 建立连接的超时 (默认 5s)
+	Timeout     time.Duration // 单次请求从发送到收到响应的超时 (默认 10s)；超时只让该请求失败，连接继续复用
+}
+
+// SocketModel 通过二进制帧协议访问远程模型，实现 core.Model。
+// 多个 goroutine 共享同一个连接，请求按 id 复用 (pipelining)；连接断开后下一次调用自动重连。
+type SocketModel struct {
+	config SocketConfig
+
+	mu      sync.Mutex // 保护 conn / pending / nextID
+	conn    *socketConn
+	pending map[uint32]chan socketReply
+	nextID  uint32
+}
+
+// socketConn 一个连接及其写缓冲
+type socketConn struct {
+	net.Conn
+	writeMu sync.Mutex // 保证一个请求帧完整地写出
+	writer  *bufio.Writer
+}
+
+type socketReply struct {
+	labels []int
+	err    error
+}
+
+// NewSocketModel 创建套接字模型客户端，第一次预测时才建立连接
+func NewSocketModel(cfg SocketConfig) *SocketModel {
+	if cfg.Network == "" {
+		cfg.Network = "tcp"
+	}
+	if cfg.Encoding == 0 {
+		cfg.Encoding = EncodingFloat32
+	}
+	if cfg.InputSize == 0 {
+		cfg.InputSize = core.FlattenedSize
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &SocketModel{config: cfg}
+}
+
+// Predict 实现 core.Model 接口
+func (m *SocketModel) Predict(img core.Image) (int, error) {
+	labels, err := m.PredictBatch([]core.Image{img})
+	if err != nil {
+		return 0, err
+	}
+	return labels[0], nil
+}
+
+// PredictBatch 实现 core.Model 接口
+func (m *SocketModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	for i, img := range imgs {
+		if len(img) != m.config.InputSize {
+			return nil, fmt.Errorf("remote: 第 %d 张图长度 %d，模型需要 %d", i, len(img), m.config.InputSize)
+		}
+	}
+
+	conn, id, reply, err := m.register()
+	if err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(m.config.Timeout)
+	defer timer.Stop()
+
+	// 写超时：服务端不再读取时，写满缓冲区的请求不会一直占着 writeMu
+	conn.writeMu.Lock()
+	conn.SetWriteDeadline(time.Now().Add(m.config.Timeout))
+	err = m.writeRequest(conn.writer, id, imgs)
+	conn.writeMu.Unlock()
+	if err != nil {
+		m.fail(conn, err)
+	}
+
+	var r socketReply
+	select {
+	case r = <-reply:
+	case <-timer.C:
+		// 迟到的响应到达时找不到登记的通道，会被 readLoop 丢弃
+		m.cancel(id)
+		return nil, fmt.Errorf("remote: 请求 %d 在 %v 内没有收到响应", id, m.config.Timeout)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.labels) != len(imgs) {
+		return nil, fmt.Errorf("remote: 请求 %d 张图，服务端返回 %d 个标签", len(imgs), len(r.labels))
+	}
+	return r.labels, nil
+}
+
+// GetInputSize 实现 core.Model 接口
+func (m *SocketModel) GetInputSize() int {
+	return m.config.InputSize
+}
+
+// Close 关闭连接，在途请求会收到错误
+func (m *SocketModel) Close() error {
+	m.mu.Lock()
+	conn := m.conn
+	m.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	m.fail(conn, net.ErrClosed)
+	return nil
+}
+
+// register 确保连接可用，分配请求 id 并登记等待响应的通道
+func (m *SocketModel) register() (*socketConn, uint32, chan socketReply, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn == nil {
+		conn, err := net.DialTimeout(m.config.Network, m.config.Address, m.config.DialTimeout)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("remote: 连接 %s %s 失败: %w", m.config.Network, m.config.Address, err)
+		}
+		m.conn = &socketConn{Conn: conn, writer: bufio.NewWriter(conn)}
+		m.pending = make(map[uint32]chan socketReply)
+		go m.readLoop(m.conn)
+	}
+
+	m.nextID++
+	reply := make(chan socketReply, 1)
+	m.pending[m.nextID] = reply
+	return m.conn, m.nextID, reply, nil
+}
+
+func (m *SocketModel) writeRequest(w *bufio.Writer, id uint32, imgs []core.Image) error {
+	dim := m.config.InputSize
+	h := frameHeader{flag: uint8(m.config.Encoding), id: id, n: uint32(len(imgs)), d: uint32(dim)}
+	if err := writeHeader(w, h); err != nil {
+		return err
+	}
+
+	var buf []byte
+	if m.config.Encoding == EncodingUint8 {
+		buf = make([]byte, dim)
+		for _, img := range imgs {
+			for i, v := range img {
+				buf[i] = dataset.ToByte(v)
+			}
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+		}
+	} else {
+		buf = make([]byte, 4*dim)
+		for _, img := range imgs {
+			for i, v := range img {
+				binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+			}
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// cancel 撤销一个超时的在途请求
+func (m *SocketModel) cancel(id uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, id)
+}
+
+// readLoop 读取响应并按 id 分发，连接出错时让所有在途请求失败
+func (m *SocketModel) readLoop(conn *socketConn) {
+	r := bufio.NewReader(conn)
+	for {
+		h, err := readHeader(r)
+		if err != nil {
+			m.fail(conn, err)
+			return
+		}
+		if h.n > maxFramePayload/4 {
+			m.fail(conn, fmt.Errorf("remote: 响应长度 %d 不合法", h.n))
+			return
+		}
+
+		var reply socketReply
+		if h.flag != 0 {
+			msg := make([]byte, h.n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				m.fail(conn, err)
+				return
+			}
+			reply.err = fmt.Errorf("remote: 服务端报告错误: %s", msg)
+		} else {
+			body := make([]byte, 4*h.n)
+			if _, err := io.ReadFull(r, body); err != nil {
+				m.fail(conn, err)
+				return
+			}
+			reply.labels = make([]int, h.n)
+			for i := range reply.labels {
+				reply.labels[i] = int(int32(binary.LittleEndian.Uint32(body[4*i:])))
+			}
+		}
+
+		m.mu.Lock()
+		ch, ok := m.pending[h.id]
+		delete(m.pending, h.id)
+		m.mu.Unlock()
+		if ok {
+			ch <- reply
+		}
+	}
+}
+
+// fail 关闭出错的连接并通知它上面所有在途请求；连接已被替换时什么也不做
+func (m *SocketModel) fail(conn *socketConn, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.conn != conn {
+		return
+	}
+
+	conn.Close()
+	m.conn = nil
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	for id, ch := range m.pending {
+		ch <- socketReply{err: fmt.Errorf("remote: 连接中断: %w", err)}
+		delete(m.pending, id)
+	}
+}
@@ -0,0 +1,228 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// SocketServerConfig 套接字服务端参数
+type SocketServerConfig struct {
+	MaxInFlight int // 每个连接同时处理的请求数 (默认 16)
+	MaxBatch    int // 单个请求的最大图片数 (默认 4096)
+}
+
+// SocketServer 把任意本地 core.Model 通过二进制帧协议提供出去，
+// 这样模型和攻击可以跑在不同进程甚至不同机器上。
+// 同一连接上的多个请求会并发调用 model.PredictBatch，model 需要是并发安全的。
+type SocketServer struct {
+	model  core.Model
+	config SocketServerConfig
+
+	mu        sync.Mutex
+	listeners map[net.Listener]bool
+	conns     map[net.Conn]bool
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewSocketServer 创建套接字服务端
+func NewSocketServer(model core.Model, cfg SocketServerConfig) *SocketServer {
+	if cfg.MaxInFlight == 0 {
+		cfg.MaxInFlight = 16
+	}
+	if cfg.MaxBatch == 0 {
+		cfg.MaxBatch = 4096
+	}
+	return &SocketServer{
+		model:     model,
+		config:    cfg,
+		listeners: make(map[net.Listener]bool),
+		conns:     make(map[net.Conn]bool),
+	}
+}
+
+// Serve 在 l 上接受连接直到 Close 被调用，此时返回 nil
+func (s *SocketServer) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listeners[l] = true
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close 关闭所有监听与连接，并等待正在处理的连接退出
+func (s *SocketServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn 逐帧读取请求，每个请求交给独立的 goroutine 推理，响应按完成顺序写回
+func (s *SocketServer) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	var writeMu sync.Mutex
+	var inFlight sync.WaitGroup
+	slots := make(chan struct{}, s.config.MaxInFlight)
+	defer inFlight.Wait()
+
+	for {
+		h, err := readHeader(r)
+		if err != nil {
+			return // 客户端断开或帧头损坏：无法再对齐后续帧
+		}
+		imgs, err := s.readImages(r, h)
+		var reqErr *requestError
+		if err != nil && !errors.As(err, &reqErr) {
+			return
+		}
+
+		slots <- struct{}{}
+		inFlight.Add(1)
+		go func() {
+			defer func() { <-slots; inFlight.Done() }()
+
+			var labels []int
+			predictErr := err
+			if predictErr == nil {
+				labels, predictErr = s.model.PredictBatch(imgs)
+			}
+
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if writeResponse(w, h.id, labels, predictErr) != nil || w.Flush() != nil {
+				conn.Close() // 让读循环退出
+			}
+		}()
+	}
+}
+
+// readImages 读取并解码一个请求的负载。
+// 先按帧头校验请求，不合法的请求在分配内存之前就被拒绝：跳过其负载并返回 *requestError
+// (连接可以继续使用)；其余错误说明连接已不可用。未知编码的负载按 float32 的宽度跳过。
+func (s *SocketServer) readImages(r io.Reader, h frameHeader) ([]core.Image, error) {
+	width := 4
+	if Encoding(h.flag) == EncodingUint8 {
+		width = 1
+	}
+	size := uint64(h.n) * uint64(h.d) * uint64(width)
+	if size > maxFramePayload {
+		return nil, fmt.Errorf("remote: 请求负载 %d 字节超过上限", size)
+	}
+
+	var reqErr *requestError
+	switch {
+	case Encoding(h.flag) != EncodingFloat32 && Encoding(h.flag) != EncodingUint8:
+		reqErr = &requestError{fmt.Sprintf("未知的像素编码 %d", h.flag)}
+	case int(h.d) != s.model.GetInputSize():
+		reqErr = &requestError{fmt.Sprintf("图片长度 %d，模型需要 %d", h.d, s.model.GetInputSize())}
+	case int(h.n) > s.config.MaxBatch:
+		reqErr = &requestError{fmt.Sprintf("批量大小 %d 超过上限 %d", h.n, s.config.MaxBatch)}
+	}
+	if reqErr != nil {
+		if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+			return nil, err
+		}
+		return nil, reqErr
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	imgs := make([]core.Image, h.n)
+	for i := range imgs {
+		img := make(core.Image, h.d)
+		for j := range img {
+			k := i*int(h.d) + j
+			if width == 1 {
+				img[j] = float32(body[k]) / 255
+			} else {
+				img[j] = math.Float32frombits(binary.LittleEndian.Uint32(body[4*k:]))
+			}
+		}
+		imgs[i] = img
+	}
+	return imgs, nil
+}
+
+func writeResponse(w io.Writer, id uint32, labels []int, predictErr error) error {
+	if predictErr != nil {
+		msg := predictErr.Error()
+		if err := writeHeader(w, frameHeader{flag: 1, id: id, n: uint32(len(msg))}); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, msg)
+		return err
+	}
+
+	if err := writeHeader(w, frameHeader{id: id, n: uint32(len(labels))}); err != nil {
+		return err
+	}
+	buf := make([]byte, 4*len(labels))
+	for i, l := range labels {
+		binary.LittleEndian.PutUint32(buf[4*i:], uint32(int32(l)))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// requestError 请求本身不合法，回给客户端而不断开连接
+type requestError struct {
+	msg string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
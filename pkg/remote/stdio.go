@@ -0,0 +1,358 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"label-only-mia-go/pkg/core"
+)
+
+// ============================================================================
+// 子进程标准输入/输出模型
+// 协议说明见同目录下的 STDIO_PROTOCOL.md (含 PyTorch 端的参考实现)。
+// 所有整数与 float32 均为小端序。
+//
+//	请求: uint32 n | uint32 d | n*d 个 float32
+//	响应: uint32 status | status == 0: uint32 n | n 个 int32 标签
+//	                     | status != 0: uint32 len | len 字节 UTF-8 错误信息
+// ============================================================================
+
+// maxStdioPayload 单个请求允许的最大像素数，防止损坏的头部导致超大内存分配
+const maxStdioPayload = 1 << 28
+
+// maxStdioBatch 单个请求最多的图片数；StdioModel.PredictBatch 会把更大的批次拆开发送
+const maxStdioBatch = 4096
+
+// maxStdioMessage 子进程错误信息的最大字节数，超过说明输出已经错位 (例如脚本误把日志打到了标准输出)
+const maxStdioMessage = 64 << 10
+
+// StdioConfig 子进程模型参数
+type StdioConfig struct {
+	Command     string    // 可执行文件，例如 python3
+	Args        []string  // 参数，例如 ["serve_model.py", "--ckpt", "model.pt"]
+	Dir         string    // 工作目录 (默认当前目录)
+	Env         []string  // 额外的环境变量 ("KEY=VALUE")，追加在当前进程环境之后
+	Stderr      io.Writer // 子进程的标准错误输出 (默认 os.Stderr，方便看到 Python 的报错)
+	InputSize   int       // 每张图的 float32 个数 (默认 core.FlattenedSize)
+	MaxRestarts int       // 一次调用中子进程退出后最多重启几次 (默认 2，负数表示不重启)
+
+	// Timeout 单个请求从发送到读完响应的超时 (默认 60s，第一个请求还包含子进程加载模型的时间)。
+	// 超时后子进程被杀掉，本次调用返回错误，下一次调用时重新启动。
+	Timeout time.Duration
+}
+
+// StdioModel 通过子进程的标准输入/输出访问本地模型 (例如 PyTorch 脚本)，实现 core.Model。
+// 子进程一次只处理一个请求，多个 goroutine 的调用在这里串行化；
+// 攻击并发度高时应优先用 PredictBatch 把多张图合成一个请求。
+type StdioModel struct {
+	config StdioConfig
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	writer   *bufio.Writer
+	reader   *bufio.Reader
+	restarts int
+}
+
+// NewStdioModel 创建子进程模型，子进程在第一次预测时才启动
+func NewStdioModel(cfg StdioConfig) *StdioModel {
+	if cfg.Stderr == nil {
+		cfg.Stderr = os.Stderr
+	}
+	if cfg.InputSize == 0 {
+		cfg.InputSize = core.FlattenedSize
+	}
+	if cfg.MaxRestarts == 0 {
+		cfg.MaxRestarts = 2
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	return &StdioModel{config: cfg}
+}
+
+// Predict 实现 core.Model 接口
+func (m *StdioModel) Predict(img core.Image) (int, error) {
+	labels, err := m.PredictBatch([]core.Image{img})
+	if err != nil {
+		return 0, err
+	}
+	return labels[0], nil
+}
+
+// PredictBatch 实现 core.Model 接口。子进程中途退出时自动重启并重发本次请求。
+func (m *StdioModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	for i, img := range imgs {
+		if len(img) != m.config.InputSize {
+			return nil, fmt.Errorf("remote: 第 %d 张图长度 %d，模型需要 %d", i, len(img), m.config.InputSize)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := make([]int, 0, len(imgs))
+	for start := 0; start < len(imgs); start += maxStdioBatch {
+		chunk, err := m.predictLocked(imgs[start:min(start+maxStdioBatch, len(imgs))])
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, chunk...)
+	}
+	return labels, nil
+}
+
+// predictLocked 发送一个请求 (调用方持有 m.mu)，子进程中途退出时重启并重发
+func (m *StdioModel) predictLocked(imgs []core.Image) ([]int, error) {
+	for attempt := 0; ; attempt++ {
+		if m.cmd == nil {
+			if err := m.start(); err != nil {
+				return nil, err
+			}
+		}
+
+		// 超时时杀掉子进程，阻塞中的读写随之返回
+		cmd := m.cmd
+		var timedOut atomic.Bool
+		timer := time.AfterFunc(m.config.Timeout, func() {
+			timedOut.Store(true)
+			cmd.Process.Kill()
+		})
+		labels, err := m.roundTrip(imgs)
+		timer.Stop()
+		if err == nil {
+			return labels, nil
+		}
+		if timedOut.Load() {
+			m.stop()
+			return nil, fmt.Errorf("remote: 子进程 %s 在 %v 内没有响应，已结束: %w", m.config.Command, m.config.Timeout, err)
+		}
+		// 子进程明确报告的错误说明它还活着，重发同样的请求也不会成功
+		var ce *childError
+		if errors.As(err, &ce) {
+			return nil, err
+		}
+
+		m.stop()
+		if attempt >= m.config.MaxRestarts {
+			return nil, fmt.Errorf("remote: 子进程 %s 通信失败 (已重启 %d 次): %w", m.config.Command, attempt, err)
+		}
+		m.restarts++
+	}
+}
+
+// GetInputSize 实现 core.Model 接口
+func (m *StdioModel) GetInputSize() int {
+	return m.config.InputSize
+}
+
+// Restarts 返回子进程累计被重启的次数
+func (m *StdioModel) Restarts() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restarts
+}
+
+// Close 关闭子进程的标准输入并等待其退出
+func (m *StdioModel) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cmd == nil {
+		return nil
+	}
+	m.stdin.Close()
+	err := m.cmd.Wait()
+	m.cmd = nil
+	return err
+}
+
+func (m *StdioModel) start() error {
+	cmd := exec.Command(m.config.Command, m.config.Args...)
+	cmd.Dir = m.config.Dir
+	if len(m.config.Env) > 0 {
+		cmd.Env = append(os.Environ(), m.config.Env...)
+	}
+	cmd.Stderr = m.config.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("remote: 启动子进程 %s 失败: %w", m.config.Command, err)
+	}
+
+	m.cmd = cmd
+	m.stdin = stdin
+	m.writer = bufio.NewWriter(stdin)
+	m.reader = bufio.NewReader(stdout)
+	return nil
+}
+
+// stop 强制结束子进程 (它可能已经退出，也可能卡在半个响应里)
+func (m *StdioModel) stop() {
+	if m.cmd == nil {
+		return
+	}
+	m.stdin.Close()
+	m.cmd.Process.Kill()
+	m.cmd.Wait()
+	m.cmd = nil
+}
+
+func (m *StdioModel) roundTrip(imgs []core.Image) ([]int, error) {
+	if err := writeStdioRequest(m.writer, imgs, m.config.InputSize); err != nil {
+		return nil, err
+	}
+	if err := m.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return readStdioResponse(m.reader, len(imgs))
+}
+
+// childError 子进程通过 status != 0 报告的错误
+type childError struct {
+	msg string
+}
+
+func (e *childError) Error() string {
+	return "remote: 子进程报告错误: " + e.msg
+}
+
+func writeStdioRequest(w io.Writer, imgs []core.Image, dim int) error {
+	buf := make([]byte, 8+4*dim)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(len(imgs)))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(dim))
+	if _, err := w.Write(buf[:8]); err != nil {
+		return err
+	}
+	for _, img := range imgs {
+		for i, v := range img {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+		}
+		if _, err := w.Write(buf[:4*dim]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStdioResponse 读取一个响应，want 为请求的图片数。
+// 长度与请求不符或错误信息过长时，输出流已经无法对齐，返回普通错误让调用方重启子进程。
+func readStdioResponse(r io.Reader, want int) ([]int, error) {
+	var head [8]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	status := binary.LittleEndian.Uint32(head[:4])
+	n := binary.LittleEndian.Uint32(head[4:])
+	if status == 0 && int(n) != want {
+		return nil, fmt.Errorf("remote: 请求 %d 张图，子进程返回 %d 个标签 (标准输出可能混入了日志)", want, n)
+	}
+	if status != 0 && n > maxStdioMessage {
+		return nil, fmt.Errorf("remote: 错误信息长度 %d 超过上限 (标准输出可能混入了日志)", n)
+	}
+
+	if status != 0 {
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return nil, err
+		}
+		return nil, &childError{msg: string(msg)}
+	}
+
+	body := make([]byte, 4*n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = int(int32(binary.LittleEndian.Uint32(body[4*i:])))
+	}
+	return labels, nil
+}
+
+// ServeStdio 在 r / w 上按同一协议提供模型服务，直到 r 读到 EOF。
+// 用它可以几行代码写出 Go 版本的子进程 (见 cmd/stdio-child)。
+// 图片长度与模型不符的请求在分配内存之前就被拒绝 (跳过请求体，回复错误后继续服务)；
+// 超过 maxStdioBatch 张图或 maxStdioPayload 个像素的请求视为损坏的数据流，直接返回错误。
+func ServeStdio(model core.Model, r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+
+	var head [8]byte
+	for {
+		if _, err := io.ReadFull(br, head[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		n := binary.LittleEndian.Uint32(head[:4])
+		dim := binary.LittleEndian.Uint32(head[4:])
+		if n > maxStdioBatch || uint64(n)*uint64(dim) > maxStdioPayload {
+			return fmt.Errorf("remote: 请求大小 %d x %d 超过上限", n, dim)
+		}
+
+		var labels []int
+		var predictErr error
+		if int(dim) != model.GetInputSize() {
+			if _, err := io.CopyN(io.Discard, br, 4*int64(n)*int64(dim)); err != nil {
+				return err
+			}
+			predictErr = fmt.Errorf("图片长度 %d，模型需要 %d", dim, model.GetInputSize())
+		} else {
+			body := make([]byte, 4*int(n)*int(dim))
+			if _, err := io.ReadFull(br, body); err != nil {
+				return err
+			}
+			imgs := make([]core.Image, n)
+			for i := range imgs {
+				imgs[i] = make(core.Image, dim)
+				for j := range imgs[i] {
+					imgs[i][j] = math.Float32frombits(binary.LittleEndian.Uint32(body[4*(i*int(dim)+j):]))
+				}
+			}
+			labels, predictErr = model.PredictBatch(imgs)
+		}
+
+		if err := writeStdioResponse(bw, labels, predictErr); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func writeStdioResponse(w io.Writer, labels []int, predictErr error) error {
+	var buf []byte
+	if predictErr != nil {
+		msg := predictErr.Error()
+		buf = binary.LittleEndian.AppendUint32(buf, 1)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(msg)))
+		buf = append(buf, msg...)
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(labels)))
+		for _, l := range labels {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(int32(l)))
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/remote"
+)
+
+// 辅助模型：第 0 个像素 > 0.5 判为 1，否则为 0
+type pixelModel struct{}
+
+func (pixelModel) Predict(img core.Image) (int, error) {
+	if img[0] > 0.5 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m pixelModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	labels := make([]int, len(imgs))
+	for i, img := range imgs {
+		labels[i], _ = m.Predict(img)
+	}
+	return labels, nil
+}
+
+func (pixelModel) GetInputSize() int { return core.FlattenedSize }
+
+// 辅助函数：第 0 个像素为 v 的图片
+func pixelImage(v float32) core.Image {
+	img := make(core.Image, core.FlattenedSize)
+	img[0] = v
+	return img
+}
+
+func TestHTTPModelRoundTrip(t *testing.T) {
+	fmt.Println("=== 测试 HTTP 模型 (单张 + 批量) ===")
+	srv := httptest.NewServer(remote.NewHandler(pixelModel{}, remote.HandlerConfig{AuthToken: "Bearer secret"}))
+	defer srv.Close()
+
+	m := remote.NewHTTPModel(remote.HTTPConfig{Endpoint: srv.URL, AuthToken: "Bearer secret"})
+	label, err := m.Predict(pixelImage(0.9))
+	if err != nil || label != 1 {
+		t.Fatalf("Predict: 期望 1, 实际 %d (err=%v)", label, err)
+	}
+
+	labels, err := m.PredictBatch([]core.Image{pixelImage(0.1), pixelImage(0.7), pixelImage(0.3)})
+	if err != nil {
+		t.Fatalf("PredictBatch 失败: %v", err)
+	}
+	if fmt.Sprint(labels) != "[0 1 0]" {
+		t.Errorf("PredictBatch: 期望 [0 1 0], 实际 %v", labels)
+	}
+}
+
+func TestHTTPModelAuthNotRetried(t *testing.T) {
+	fmt.Println("=== 测试 HTTP 模型 (认证失败不重试) ===")
+	var calls atomic.Int32
+	handler := remote.NewHandler(pixelModel{}, remote.HandlerConfig{AuthToken: "Bearer secret"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	m := remote.NewHTTPModel(remote.HTTPConfig{Endpoint: srv.URL, AuthToken: "Bearer wrong", RetryBackoff: time.Millisecond})
+	if _, err := m.Predict(pixelImage(0.9)); err == nil {
+		t.Fatal("错误的令牌应当返回错误")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("401 不应重试: 服务端收到 %d 次请求", calls.Load())
+	}
+}
+
+func TestHTTPModelRetry(t *testing.T) {
+	fmt.Println("=== 测试 HTTP 模型 (5xx 重试) ===")
+	var calls atomic.Int32
+	handler := remote.NewHandler(pixelModel{}, remote.HandlerConfig{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 前两次模拟服务过载
+		if calls.Add(1) <= 2 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	m := remote.NewHTTPModel(remote.HTTPConfig{Endpoint: srv.URL, RetryBackoff: time.Millisecond})
+	label, err := m.Predict(pixelImage(0.9))
+	if err != nil || label != 1 {
+		t.Fatalf("重试后应成功: label=%d err=%v", label, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("期望 3 次请求, 实际 %d", calls.Load())
+	}
+}
+
+func TestHTTPModelManyRetries(t *testing.T) {
+	fmt.Println("=== 测试 HTTP 模型 (重试次数很多或退避为负时不崩溃) ===")
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// 1ns << 70 会溢出；负的退避表示立即重试
+	for _, backoff := range []time.Duration{time.Nanosecond, -1} {
+		calls.Store(0)
+		m := remote.NewHTTPModel(remote.HTTPConfig{Endpoint: srv.URL, MaxRetries: 70, RetryBackoff: backoff})
+		if _, err := m.Predict(pixelImage(0.9)); err == nil {
+			t.Fatal("服务端一直过载时应当返回错误")
+		}
+		if calls.Load() != 71 {
+			t.Errorf("退避 %v: 期望 71 次请求, 实际 %d", backoff, calls.Load())
+		}
+	}
+}
+
+func TestHTTPModelBadResponseNotRetried(t *testing.T) {
+	fmt.Println("=== 测试 HTTP 模型 (200 响应无法解析时不重试) ===")
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("<html>not json</html>"))
+	}))
+	defer srv.Close()
+
+	m := remote.NewHTTPModel(remote.HTTPConfig{Endpoint: srv.URL, RetryBackoff: time.Millisecond})
+	if _, err := m.Predict(pixelImage(0.9)); err == nil {
+		t.Fatal("无法解析的响应应当返回错误")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("协议不匹配不应重试: 服务端收到 %d 次请求", calls.Load())
+	}
+}
+
+func TestHTTPModelTimeout(t *testing.T) {
+	fmt.Println("=== 测试 HTTP 模型 (超时) ===")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	m := remote.NewHTTPModel(remote.HTTPConfig{Endpoint: srv.URL, Timeout: 20 * time.Millisecond, MaxRetries: -1})
+	start := time.Now()
+	if _, err := m.Predict(pixelImage(0.9)); err == nil {
+		t.Fatal("服务端无响应时应当超时")
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Errorf("超时没有生效: 用时 %v", time.Since(start))
+	}
+}
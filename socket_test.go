@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/remote"
+)
+
+// 辅助监听器：记录服务端接受的连接数
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+// 辅助函数：在 network 上启动包装 model 的套接字服务，返回监听器 (地址与连接数) 与关闭函数
+func startSocketServer(t *testing.T, network, address string, model core.Model) (*countingListener, func()) {
+	t.Helper()
+	l, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	cl := &countingListener{Listener: l}
+	srv := remote.NewSocketServer(model, remote.SocketServerConfig{})
+	go srv.Serve(cl)
+	return cl, func() { srv.Close() }
+}
+
+// 辅助模型：第 1 个像素 > 0 时报告错误，第 2 个像素 > 0 时先卡住 200ms，其余同 pixelModel
+type faultyModel struct{ pixelModel }
+
+func (m faultyModel) PredictBatch(imgs []core.Image) ([]int, error) {
+	for _, img := range imgs {
+		if img[1] > 0 {
+			return nil, fmt.Errorf("模型推理失败")
+		}
+		if img[2] > 0 {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}
+	return m.pixelModel.PredictBatch(imgs)
+}
+
+// 辅助函数：第 k 个像素为 1 的图片，用来触发 faultyModel 的故障
+func faultImage(k int) core.Image {
+	img := pixelImage(0.9)
+	img[k] = 1
+	return img
+}
+
+func TestSocketModelPipelining(t *testing.T) {
+	fmt.Println("=== 测试套接字模型 (TCP，多请求在途) ===")
+	l, stop := startSocketServer(t, "tcp", "127.0.0.1:0", pixelModel{})
+	defer stop()
+
+	m := remote.NewSocketModel(remote.SocketConfig{Network: "tcp", Address: l.Addr().String()})
+	defer m.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			v := float32(g%2) * 0.9
+			for i := 0; i < 50; i++ {
+				label, err := m.Predict(pixelImage(v))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if label != g%2 {
+					errs <- fmt.Errorf("goroutine %d: 期望 %d, 实际 %d", g, g%2, label)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSocketModelUnixUint8(t *testing.T) {
+	fmt.Println("=== 测试套接字模型 (Unix，uint8 编码) ===")
+	l, stop := startSocketServer(t, "unix", filepath.Join(t.TempDir(), "model.sock"), pixelModel{})
+	defer stop()
+
+	m := remote.NewSocketModel(remote.SocketConfig{Network: "unix", Address: l.Addr().String(), Encoding: remote.EncodingUint8})
+	defer m.Close()
+
+	labels, err := m.PredictBatch([]core.Image{pixelImage(0.2), pixelImage(0.8), pixelImage(1.0)})
+	if err != nil {
+		t.Fatalf("PredictBatch 失败: %v", err)
+	}
+	if fmt.Sprint(labels) != "[0 1 1]" {
+		t.Errorf("期望 [0 1 1], 实际 %v", labels)
+	}
+}
+
+func TestSocketModelServerError(t *testing.T) {
+	fmt.Println("=== 测试套接字模型 (服务端报告错误后连接仍可用) ===")
+	l, stop := startSocketServer(t, "tcp", "127.0.0.1:0", faultyModel{})
+	defer stop()
+
+	m := remote.NewSocketModel(remote.SocketConfig{Address: l.Addr().String()})
+	defer m.Close()
+	if label, err := m.Predict(pixelImage(0.9)); err != nil || label != 1 {
+		t.Fatalf("label=%d err=%v", label, err)
+	}
+	if _, err := m.Predict(faultImage(1)); err == nil {
+		t.Fatal("模型出错时服务端应当报告错误")
+	}
+	if label, err := m.Predict(pixelImage(0.9)); err != nil || label != 1 {
+		t.Fatalf("报告错误后同一客户端应继续可用: label=%d err=%v", label, err)
+	}
+	if n := l.accepted.Load(); n != 1 {
+		t.Errorf("服务端报告的错误不应断开连接: 建立了 %d 个连接", n)
+	}
+}
+
+func TestSocketModelTimeout(t *testing.T) {
+	fmt.Println("=== 测试套接字模型 (请求超时后连接仍可用) ===")
+	l, stop := startSocketServer(t, "tcp", "127.0.0.1:0", faultyModel{})
+	defer stop()
+
+	m := remote.NewSocketModel(remote.SocketConfig{Address: l.Addr().String(), Timeout: 20 * time.Millisecond})
+	defer m.Close()
+	start := time.Now()
+	if _, err := m.Predict(faultImage(2)); err == nil {
+		t.Fatal("服务端无响应时应当超时")
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Errorf("超时没有生效: 用时 %v", time.Since(start))
+	}
+
+	// 迟到的响应被丢弃，不会错发给后面的请求
+	time.Sleep(250 * time.Millisecond)
+	if label, err := m.Predict(pixelImage(0.1)); err != nil || label != 0 {
+		t.Fatalf("超时后同一客户端应继续可用: label=%d err=%v", label, err)
+	}
+	if n := l.accepted.Load(); n != 1 {
+		t.Errorf("超时不应断开连接: 建立了 %d 个连接", n)
+	}
+}
+
+// 辅助函数：按套接字协议编码一个 float32 请求帧 (像素全为 0)
+func socketFrame(id, n, d uint32) []byte {
+	buf := binary.LittleEndian.AppendUint16(nil, 0x4D49)
+	buf = append(buf, 1, byte(remote.EncodingFloat32))
+	buf = binary.LittleEndian.AppendUint32(buf, id)
+	buf = binary.LittleEndian.AppendUint32(buf, n)
+	buf = binary.LittleEndian.AppendUint32(buf, d)
+	return append(buf, make([]byte, 4*n*d)...)
+}
+
+func TestSocketServerRejectsBadFrames(t *testing.T) {
+	fmt.Println("=== 测试套接字服务端 (图片长度不符、批次过大时跳过负载) ===")
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	srv := remote.NewSocketServer(pixelModel{}, remote.SocketServerConfig{MaxBatch: 2, MaxInFlight: 1})
+	go srv.Serve(l)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write(socketFrame(1, 3, 10))                 // 长度不符
+	conn.Write(socketFrame(2, 3, core.FlattenedSize)) // 超过 MaxBatch
+	conn.Write(socketFrame(3, 1, core.FlattenedSize)) // 正常请求
+
+	// MaxInFlight 为 1，响应按请求顺序返回
+	for _, want := range []struct {
+		id     uint32
+		status byte
+	}{{1, 1}, {2, 1}, {3, 0}} {
+		head := make([]byte, 16)
+		if _, err := io.ReadFull(conn, head); err != nil {
+			t.Fatalf("请求 %d 没有收到响应: %v", want.id, err)
+		}
+		id, n := binary.LittleEndian.Uint32(head[4:]), binary.LittleEndian.Uint32(head[8:])
+		if id != want.id || head[3] != want.status {
+			t.Errorf("期望请求 %d 的状态 %d, 实际请求 %d 的状态 %d", want.id, want.status, id, head[3])
+		}
+		width := uint32(1)
+		if head[3] == 0 {
+			width = 4
+		}
+		io.CopyN(io.Discard, conn, int64(n*width))
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/remote"
+)
+
+// 辅助函数：编译 cmd/stdio-child 替身子进程，返回可执行文件路径
+func buildStdioChild(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "stdio-child")
+	out, err := exec.Command("go", "build", "-o", bin, "./cmd/stdio-child").CombinedOutput()
+	if err != nil {
+		t.Fatalf("编译 stdio-child 失败: %v\n%s", err, out)
+	}
+	return bin
+}
+
+func TestStdioModelConcurrent(t *testing.T) {
+	fmt.Println("=== 测试子进程模型 (并发调用) ===")
+	m := remote.NewStdioModel(remote.StdioConfig{Command: buildStdioChild(t)})
+	defer m.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			v := float32(g%2) * 0.9 // 偶数 goroutine 期望 0，奇数期望 1
+			for i := 0; i < 20; i++ {
+				labels, err := m.PredictBatch([]core.Image{pixelImage(v), pixelImage(0.6)})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if labels[0] != g%2 || labels[1] != 1 {
+					errs <- fmt.Errorf("goroutine %d: 标签 %v", g, labels)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestStdioModelRestart(t *testing.T) {
+	fmt.Println("=== 测试子进程模型 (子进程退出后重启) ===")
+	m := remote.NewStdioModel(remote.StdioConfig{
+		Command: buildStdioChild(t),
+		Args:    []string{"-die-after", "2"},
+		Stderr:  io.Discard,
+	})
+	defer m.Close()
+
+	for i := 0; i < 5; i++ {
+		label, err := m.Predict(pixelImage(0.9))
+		if err != nil || label != 1 {
+			t.Fatalf("第 %d 次预测: label=%d err=%v", i, label, err)
+		}
+	}
+	if m.Restarts() != 2 {
+		t.Errorf("期望重启 2 次, 实际 %d", m.Restarts())
+	}
+}
+
+func TestStdioModelChildError(t *testing.T) {
+	fmt.Println("=== 测试子进程模型 (子进程报告错误) ===")
+	m := remote.NewStdioModel(remote.StdioConfig{
+		Command:   buildStdioChild(t),
+		Args:      []string{"-size", "10"},
+		InputSize: core.FlattenedSize,
+	})
+	defer m.Close()
+
+	if _, err := m.Predict(pixelImage(0.9)); err == nil {
+		t.Fatal("尺寸不匹配时子进程应当报告错误")
+	}
+	if m.Restarts() != 0 {
+		t.Errorf("子进程报告的错误不应触发重启 (重启 %d 次)", m.Restarts())
+	}
+}
+
+// 辅助函数：按 stdio 协议编码一个请求头 (n, d) 及 body 个 float32 (像素全为 0)
+func stdioFrame(n, d, body uint32) []byte {
+	buf := binary.LittleEndian.AppendUint32(nil, n)
+	buf = binary.LittleEndian.AppendUint32(buf, d)
+	return append(buf, make([]byte, 4*body)...)
+}
+
+func TestServeStdioRejectsBadFrames(t *testing.T) {
+	fmt.Println("=== 测试 ServeStdio (图片长度不符、批次过大) ===")
+	var in bytes.Buffer
+	in.Write(stdioFrame(2, 10, 20))                                 // 长度不符：回复错误后继续服务
+	in.Write(stdioFrame(1, core.FlattenedSize, core.FlattenedSize)) // 正常请求
+	in.Write(stdioFrame(1<<20, 1<<8, 0))                            // 像素总数在上限内，但图片数超过上限
+
+	var out bytes.Buffer
+	if err := remote.ServeStdio(pixelModel{}, &in, &out); err == nil {
+		t.Error("图片数超过上限时应当返回错误")
+	}
+
+	words := make([]uint32, out.Len()/4)
+	binary.Read(bytes.NewReader(out.Bytes()), binary.LittleEndian, words)
+	if len(words) < 2 || words[0] != 1 {
+		t.Fatalf("长度不符的请求应当收到错误响应: %v", out.Bytes())
+	}
+	msgLen := int(words[1])
+	rest := out.Bytes()[8+msgLen:]
+	if fmt.Sprint(rest) != fmt.Sprint([]byte{0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("错误之后的正常请求应返回 1 个标签 0, 实际 %v", rest)
+	}
+}
+
+func TestStdioModelGarbledOutput(t *testing.T) {
+	fmt.Println("=== 测试子进程模型 (标准输出混入日志时不按乱码长度分配内存) ===")
+	// "load" 被读成 status，"ing\n" 被读成约 1.7 亿字节的长度
+	m := remote.NewStdioModel(remote.StdioConfig{
+		Command:     "sh",
+		Args:        []string{"-c", "printf 'loading\\n'; exec sleep 60"},
+		MaxRestarts: -1,
+		Timeout:     5 * time.Second,
+	})
+	defer m.Close()
+
+	start := time.Now()
+	if _, err := m.Predict(pixelImage(0.9)); err == nil {
+		t.Fatal("错位的输出应当返回错误")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("不应等到超时才发现输出错位: 用时 %v", time.Since(start))
+	}
+}
+
+func TestStdioModelTimeout(t *testing.T) {
+	fmt.Println("=== 测试子进程模型 (子进程无响应时超时并结束) ===")
+	m := remote.NewStdioModel(remote.StdioConfig{
+		Command: "sh",
+		Args:    []string{"-c", "exec sleep 60"},
+		Timeout: 100 * time.Millisecond,
+	})
+	defer m.Close()
+
+	start := time.Now()
+	if _, err := m.Predict(pixelImage(0.9)); err == nil {
+		t.Fatal("子进程无响应时应当超时")
+	}
+	// 超时不重发：不会等满 (MaxRestarts + 1) 个超时
+	if d := time.Since(start); d < 100*time.Millisecond || d > 250*time.Millisecond {
+		t.Errorf("超时没有生效或被重试: 用时 %v", d)
+	}
+}
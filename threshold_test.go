@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"label-only-mia-go/pkg/core"
+	"label-only-mia-go/pkg/eval"
+)
+
+// 辅助函数：n 个攻击成功的非成员 (距离 0, 0.01, ...) 加上 failed 个攻击失败的非成员
+func nonMembersWithFailures(n, failed int) []core.AttackResult {
+	var results []core.AttackResult
+	for i := 0; i < n; i++ {
+		results = append(results, core.AttackResult{SampleID: i, IsSuccess: true, Distance: float64(i) * 0.01})
+	}
+	for i := 0; i < failed; i++ {
+		results = append(results, core.AttackResult{SampleID: n + i, IsSuccess: false})
+	}
+	return results
+}
+
+// 辅助函数：阈值在这批非成员上实际达到的 FPR
+func achievedFPR(t *eval.Threshold, nonMembers []core.AttackResult) float64 {
+	fp := 0
+	for _, r := range t.Apply(nonMembers) {
+		if r.IsMember {
+			fp++
+		}
+	}
+	return float64(fp) / float64(len(nonMembers))
+}
+
+func TestFixedFPRCountsFailedNonMembers(t *testing.T) {
+	fmt.Println("=== 测试固定 FPR 校准 (攻击失败的非成员计入误判名额) ===")
+	nonMembers := nonMembersWithFailures(95, 5)
+
+	for _, target := range []float64{0.05, 0.1, 0.3} {
+		thr, err := eval.CalibrateFixedFPR(nonMembers, target, eval.HigherIsMember)
+		if err != nil {
+			t.Fatalf("FPR %.2f: %v", target, err)
+		}
+		got := achievedFPR(thr, nonMembers)
+		fmt.Printf("  目标 FPR %.2f -> 实际 %.2f (阈值 %.4f)\n", target, got, thr.Value)
+		if got > target+1e-12 {
+			t.Errorf("目标 FPR %.2f, 实际 %.2f", target, got)
+		}
+	}
+
+	// 5 个失败样本本身就是 5% 的假阳性，1% 的目标无法达到
+	if _, err := eval.CalibrateFixedFPR(nonMembers, 0.01, eval.HigherIsMember); err == nil {
+		t.Error("攻击失败的非成员超过名额时应当返回错误")
+	}
+}
+
+func TestFixedFPRLowerIsMember(t *testing.T) {
+	fmt.Println("=== 测试固定 FPR 校准 (LowerIsMember，失败样本永远不是成员) ===")
+	nonMembers := nonMembersWithFailures(95, 5)
+	thr, err := eval.CalibrateFixedFPR(nonMembers, 0.01, eval.LowerIsMember)
+	if err != nil {
+		t.Fatalf("校准失败: %v", err)
+	}
+	// k = 1：只有距离最小的 0 被判为成员
+	if got := achievedFPR(thr, nonMembers); got != 0.01 {
+		t.Errorf("期望实际 FPR 0.01, 实际 %.4f", got)
+	}
+}
+
+func TestMaxAccuracyAndPersistence(t *testing.T) {
+	fmt.Println("=== 测试最大准确率校准与阈值文件读写 ===")
+	results := resultsFromDistances([]float64{0.9, 0.8, 0.7, 0.3, 0.2, 0.1})
+	members := []bool{true, true, true, false, false, false}
+
+	thr, err := eval.CalibrateMaxAccuracy(results, members, eval.HigherIsMember)
+	if err != nil {
+		t.Fatalf("校准失败: %v", err)
+	}
+	// 完全可分：阈值取最低的成员分数 0.7
+	if thr.Value != 0.7 {
+		t.Errorf("期望阈值 0.7, 实际 %.4f", thr.Value)
+	}
+
+	path := filepath.Join(t.TempDir(), "threshold.json")
+	if err := thr.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	loaded, err := eval.LoadThreshold(path)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+
+	prev := -1.0
+	for i, r := range loaded.Apply(results) {
+		if r.IsMember != members[i] {
+			t.Errorf("样本 %d: 期望成员 %v", i, members[i])
+		}
+		if (r.MembershipScore > 0.5) != members[i] {
+			t.Errorf("样本 %d: Platt 成员分数 %.4f 与真值不符", i, r.MembershipScore)
+		}
+		// 分数由大到小排列，Platt 分数也必须单调
+		if prev >= 0 && r.MembershipScore > prev {
+			t.Errorf("Platt 分数不单调: %.4f > %.4f", r.MembershipScore, prev)
+		}
+		prev = r.MembershipScore
+	}
+	if math.IsNaN(prev) {
+		t.Error("Platt 分数出现 NaN")
+	}
+}
+
+func TestPerClassThresholds(t *testing.T) {
+	fmt.Println("=== 测试按类别阈值 (类别难度不同) ===")
+	// 类别 0 整体离边界远，类别 1 整体离边界近：全局阈值无法同时分开两类
+	dists := []float64{0.9, 0.8, 0.5, 0.4, 0.3, 0.25, 0.1, 0.05, 0.6}
+	labels := []int{0, 0, 0, 0, 1, 1, 1, 1, 2}
+	members := []bool{true, true, false, false, true, true, false, false, true}
+	results := resultsFromDistances(dists)
+	for i := range results {
+		results[i].OriginalLabel = labels[i]
+	}
+
+	ct, err := eval.CalibratePerClass(results, members, eval.MaxAccuracyCalibrator(eval.HigherIsMember), 2)
+	if err != nil {
+		t.Fatalf("校准失败: %v", err)
+	}
+	if ct.For(2) != ct.Global {
+		t.Error("样本不足的类别 2 应回退到全局阈值")
+	}
+
+	path := filepath.Join(t.TempDir(), "per_class.json")
+	if err := ct.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	loaded, err := eval.LoadClassThresholds(path)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	for i, r := range loaded.Apply(results) {
+		if labels[i] != 2 && r.IsMember != members[i] {
+			t.Errorf("样本 %d (类别 %d): 期望成员 %v", i, labels[i], members[i])
+		}
+	}
+
+	reports, err := eval.EvaluateByClass(results, members, eval.HigherIsMember, eval.BootstrapConfig{})
+	if err != nil {
+		t.Fatalf("按类别评估失败: %v", err)
+	}
+	fmt.Print(eval.FormatClassReports(reports))
+	// 类别 2 只有成员，无法计算 AUC；其余两类完全可分，同 AUC 时按标签排序
+	if len(reports) != 2 || reports[0].Label != 0 || reports[1].Label != 1 || reports[0].Report.AUC != 1 || reports[1].Report.AUC != 1 {
+		t.Errorf("按类别报告不符: %+v", reports)
+	}
+}